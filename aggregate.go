@@ -0,0 +1,185 @@
+package datatable
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Aggregation - a function that combines the values of many rows into one
+type Aggregation int
+
+// Aggregations
+const (
+	AggSum Aggregation = iota
+	AggCount
+	AggAvg
+	AggMin
+	AggMax
+	AggFirst
+	AggLast
+)
+
+// aggregator - accumulates values for an aggregation. Null values are skipped
+type aggregator struct {
+	agg     Aggregation
+	count   int64
+	isum    int64
+	fsum    float64
+	isFloat bool
+	value   interface{}
+}
+
+func newAggregator(agg Aggregation) *aggregator {
+	return &aggregator{agg: agg}
+}
+
+func (a *aggregator) add(value interface{}) {
+	if value == nil {
+		return
+	}
+	a.count++
+
+	switch a.agg {
+	case AggSum, AggAvg:
+		switch v := normalizeValue(value).(type) {
+		case int64:
+			// Whole floats normalize to int64, so the kind of the raw value decides whether the sum is a float
+			switch value.(type) {
+			case float32, float64:
+				a.isFloat = true
+			}
+			a.isum += v
+			a.fsum += float64(v)
+		default:
+			if f, ok := toFloat64(value); ok {
+				a.isFloat = true
+				a.fsum += f
+			} else if str, ok := v.(string); ok {
+				// DECIMAL values may arrive as text
+				if f, err := strconv.ParseFloat(str, 64); err == nil {
+					a.isFloat = true
+					a.fsum += f
+				}
+			}
+		}
+	case AggMin:
+		if a.value == nil || compareValues(value, a.value) < 0 {
+			a.value = value
+		}
+	case AggMax:
+		if a.value == nil || compareValues(value, a.value) > 0 {
+			a.value = value
+		}
+	case AggFirst:
+		if a.count == 1 {
+			a.value = value
+		}
+	case AggLast:
+		a.value = value
+	}
+}
+
+// result - returns the aggregated value. Sum and average of no values are null
+func (a *aggregator) result() interface{} {
+	switch a.agg {
+	case AggCount:
+		return a.count
+	case AggSum:
+		if a.count == 0 {
+			return nil
+		}
+		if a.isFloat {
+			return a.fsum
+		}
+		return a.isum
+	case AggAvg:
+		if a.count == 0 {
+			return nil
+		}
+		return a.fsum / float64(a.count)
+	}
+	return a.value
+}
+
+// resultAs - returns the aggregated value, converting an integer sum to float64 for a float64 output column
+func (a *aggregator) resultAs(t reflect.Type) interface{} {
+	v := a.result()
+	if i, ok := v.(int64); ok && t != nil && t.Kind() == reflect.Float64 {
+		return float64(i)
+	}
+	return v
+}
+
+// aggregateType - returns the type of the result of an aggregation over values of type t
+func aggregateType(agg Aggregation, t reflect.Type) reflect.Type {
+	switch agg {
+	case AggCount:
+		return reflect.TypeOf(int64(0))
+	case AggAvg:
+		return reflect.TypeOf(float64(0))
+	case AggSum:
+		if t != nil && isIntKind(t.Kind()) {
+			return reflect.TypeOf(int64(0))
+		}
+		return reflect.TypeOf(float64(0))
+	}
+	return t
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// compareValues - orders two values. Nulls sort first, numbers compare numerically, times by instant
+// and anything else by its string form. Returns -1, 0 or 1
+func compareValues(a, b interface{}) int {
+	na, nb := normalizeValue(a), normalizeValue(b)
+	switch {
+	case na == nil && nb == nil:
+		return 0
+	case na == nil:
+		return -1
+	case nb == nil:
+		return 1
+	}
+
+	if ta, ok := na.(time.Time); ok {
+		if tb, ok := nb.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+
+	if ia, ok := na.(int64); ok {
+		if ib, ok := nb.(int64); ok {
+			return cmp.Compare(ia, ib)
+		}
+	}
+
+	fa, oka := toFloat64(na)
+	fb, okb := toFloat64(nb)
+	if oka && okb {
+		return cmp.Compare(fa, fb)
+	}
+
+	if ba, ok := na.(bool); ok {
+		if bb, ok := nb.(bool); ok {
+			switch {
+			case ba == bb:
+				return 0
+			case !ba:
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return cmp.Compare(fmt.Sprint(na), fmt.Sprint(nb))
+}
@@ -0,0 +1,61 @@
+package datatable
+
+import (
+	"database/sql"
+	"database/sql/driver"
+)
+
+// Args - returns cell values for binding to positional statement parameters, in the order of the
+// column names given or in cell order if none are given. Columns that do not exist bind as NULL
+func (rw *Row) Args(cols ...string) []interface{} {
+	if len(cols) == 0 {
+		args := make([]interface{}, len(rw.Cells))
+		for i := range rw.Cells {
+			args[i] = argValue(rw.Cells[i].Value)
+		}
+		return args
+	}
+
+	args := make([]interface{}, len(cols))
+	for i, name := range cols {
+		if idx := rw.cellIndex(name); idx != -1 {
+			args[i] = argValue(rw.Cells[idx].Value)
+		}
+	}
+	return args
+}
+
+// NamedArgs - returns sql.NamedArg values named by the cell column names for binding to named statement
+// parameters. Only the named columns are returned if any are given, and columns that do not exist are skipped
+func (rw *Row) NamedArgs(cols ...string) []interface{} {
+	if len(cols) == 0 {
+		args := make([]interface{}, len(rw.Cells))
+		for i, c := range rw.Cells {
+			args[i] = sql.Named(c.ColumnName, argValue(c.Value))
+		}
+		return args
+	}
+
+	args := make([]interface{}, 0, len(cols))
+	for _, name := range cols {
+		if idx := rw.cellIndex(name); idx != -1 {
+			c := rw.Cells[idx]
+			args = append(args, sql.Named(c.ColumnName, argValue(c.Value)))
+		}
+	}
+	return args
+}
+
+// argValue - converts a cell value to a driver value. Values implementing driver.Valuer, such as
+// decimal types, are resolved and integer kinds are widened. Values the default converter does
+// not support are passed as they are so that the driver can still handle them
+func argValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return v
+	}
+	return dv
+}
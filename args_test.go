@@ -0,0 +1,74 @@
+package datatable
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eaglebush/datatable/datatabletest"
+)
+
+// testDecimal - a decimal type that binds as text
+type testDecimal string
+
+func (d testDecimal) Value() (driver.Value, error) {
+	return string(d), nil
+}
+
+func TestRowArgs(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Price", Type: reflect.TypeOf(testDecimal(""))},
+		{Name: "Created", Type: reflect.TypeOf(time.Time{})},
+		{Name: "Data", Type: reflect.TypeOf([]byte{})},
+	})
+	r := dt.NewRow()
+	r.Cells[0].Value = int32(7)
+	r.Cells[1].Value = testDecimal("0.05")
+	r.Cells[2].Value = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	r.Cells[3].Value = []byte{1, 2}
+
+	want := []interface{}{int64(7), "0.05", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), []byte{1, 2}}
+	if got := r.Args(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, expected %v", got, want)
+	}
+
+	want = []interface{}{"0.05", nil, int64(7)}
+	if got := r.Args("price", "missing", "ID"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, expected %v", got, want)
+	}
+}
+
+func TestRowNamedArgs(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Price", Type: reflect.TypeOf(testDecimal(""))},
+		{Name: "Data", Type: reflect.TypeOf([]byte{})},
+	})
+	r := dt.NewRow()
+	r.Cells[0].Value = int32(7)
+	r.Cells[1].Value = testDecimal("0.05")
+
+	args := r.NamedArgs("price", "missing", "ID")
+	want := []interface{}{sql.Named("Price", "0.05"), sql.Named("ID", int64(7))}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("got %v, expected %v", args, want)
+	}
+	if got := r.NamedArgs(); len(got) != 3 || got[2].(sql.NamedArg).Name != "Data" {
+		t.Fatalf("unexpected named arguments %v", got)
+	}
+
+	db := datatabletest.Open()
+	defer db.Close()
+	if _, err := db.Exec("UPDATE Items SET Price = @Price WHERE ID = @ID", args...); err != nil {
+		t.Fatal(err)
+	}
+	execs := datatabletest.Execs(db)
+	if len(execs) != 1 || !reflect.DeepEqual(execs[0].Args, []driver.Value{"0.05", int64(7)}) {
+		t.Fatalf("unexpected statements %v", execs)
+	}
+}
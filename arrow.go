@@ -0,0 +1,639 @@
+package datatable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Arrow IPC constants from Schema.fbs and Message.fbs
+const (
+	arrowMagic           = "ARROW1"
+	arrowMetadataV5      = 4
+	arrowHeaderSchema    = 1
+	arrowHeaderRecord    = 3
+	arrowTypeNull        = 1
+	arrowTypeInt         = 2
+	arrowTypeFloat       = 3
+	arrowTypeBinary      = 4
+	arrowTypeUtf8        = 5
+	arrowTypeBool        = 6
+	arrowTypeDecimal     = 7
+	arrowTypeDate        = 8
+	arrowTypeTimestamp   = 10
+	arrowTypeLargeBinary = 19
+	arrowTypeLargeUtf8   = 20
+	arrowMicrosecond     = 2
+	arrowContinuation    = 0xFFFFFFFF
+)
+
+// tableNameKey - the schema metadata key holding the table name in Arrow and Parquet files
+const tableNameKey = "datatable.name"
+
+// defaultBatchSize - rows per Arrow record batch or Parquet row group
+const defaultBatchSize = 65536
+
+// ArrowOptions - options for WriteArrow
+type ArrowOptions struct {
+	BatchSize int // rows per record batch. Defaults to 65536
+}
+
+// WriteArrow - writes the table as an Arrow IPC file. Integers are written as int64, floats as float64,
+// strings as utf8, times as UTC timestamps in microseconds, []byte as binary and DECIMAL or NUMERIC
+// columns as decimal128. NULL cells are marked in the validity bitmap of each column. Rows are written
+// in record batches so that only one batch is held in memory at a time
+func (dt *DataTable) WriteArrow(w io.Writer, opts ArrowOptions) error {
+	fields, err := dt.columnarFields()
+	if err != nil {
+		return err
+	}
+	batch := opts.BatchSize
+	if batch <= 0 {
+		batch = defaultBatchSize
+	}
+
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write([]byte(arrowMagic + "\x00\x00")); err != nil {
+		return err
+	}
+
+	schema := dt.arrowSchema(fields)
+	if _, err := writeArrowMessage(cw, arrowHeaderSchema, schema, nil); err != nil {
+		return err
+	}
+
+	var blocks []byte
+	for start := 0; start < len(dt.Rows); start += batch {
+		end := min(start+batch, len(dt.Rows))
+		header, body, err := dt.arrowRecordBatch(fields, start, end)
+		if err != nil {
+			return err
+		}
+
+		offset := cw.n
+		metaLen, err := writeArrowMessage(cw, arrowHeaderRecord, header, body)
+		if err != nil {
+			return err
+		}
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(offset))
+		blocks = binary.LittleEndian.AppendUint32(blocks, uint32(metaLen))
+		blocks = binary.LittleEndian.AppendUint32(blocks, 0)
+		blocks = binary.LittleEndian.AppendUint64(blocks, uint64(len(body)))
+	}
+
+	// End of stream, then the footer for random access
+	eos := binary.LittleEndian.AppendUint32(nil, arrowContinuation)
+	eos = binary.LittleEndian.AppendUint32(eos, 0)
+	if _, err := cw.Write(eos); err != nil {
+		return err
+	}
+
+	footer := fbBuild(fbTable{
+		fbInt16(arrowMetadataV5),
+		fbRef(schema),
+		fbRef(fbStructs{align: 8, size: 24}),
+		fbRef(fbStructs{align: 8, size: 24, data: blocks}),
+	})
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	footer = append(footer, arrowMagic...)
+	_, err = cw.Write(footer)
+	return err
+}
+
+// arrowSchema - returns the Schema table of the fields
+func (dt *DataTable) arrowSchema(fields []columnarField) fbTable {
+	list := make(fbVector, len(fields))
+	for i, f := range fields {
+		var typeID uint8
+		var typ fbTable
+		switch f.kind {
+		case columnarInt64:
+			typeID, typ = arrowTypeInt, fbTable{fbInt32(64), fbBool(true)}
+		case columnarFloat64:
+			typeID, typ = arrowTypeFloat, fbTable{fbInt16(2)}
+		case columnarBool:
+			typeID, typ = arrowTypeBool, fbTable{}
+		case columnarTime:
+			typeID, typ = arrowTypeTimestamp, fbTable{fbInt16(arrowMicrosecond), fbRef(fbString("UTC"))}
+		case columnarBinary:
+			typeID, typ = arrowTypeBinary, fbTable{}
+		case columnarDecimal:
+			typeID, typ = arrowTypeDecimal, fbTable{fbInt32(int32(f.precision)), fbInt32(int32(f.scale)), fbInt32(128)}
+		default:
+			typeID, typ = arrowTypeUtf8, fbTable{}
+		}
+		list[i] = fbTable{
+			fbRef(fbString(f.name)),
+			fbBool(f.nullable),
+			fbUint8(typeID),
+			fbRef(typ),
+			{},
+			fbRef(fbVector{}),
+		}
+	}
+
+	meta := fbVector{fbTable{fbRef(fbString(tableNameKey)), fbRef(fbString(dt.Name))}}
+	return fbTable{fbInt16(0), fbRef(list), fbRef(meta)}
+}
+
+// arrowRecordBatch - returns the RecordBatch table and the body of a range of rows
+func (dt *DataTable) arrowRecordBatch(fields []columnarField, start, end int) (fbTable, []byte, error) {
+	n := end - start
+	var body, nodes, buffers []byte
+
+	addBuffer := func(b []byte) {
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(body)))
+		buffers = binary.LittleEndian.AppendUint64(buffers, uint64(len(b)))
+		body = append(body, b...)
+		for len(body)%8 != 0 {
+			body = append(body, 0)
+		}
+	}
+
+	for _, f := range fields {
+		valid := make([]byte, (n+7)/8)
+		nulls := 0
+		var values, data []byte
+
+		switch f.kind {
+		case columnarInt64, columnarFloat64, columnarTime:
+			values = make([]byte, 8*n)
+		case columnarDecimal:
+			values = make([]byte, 16*n)
+		case columnarBool:
+			values = make([]byte, (n+7)/8)
+		default:
+			values = make([]byte, 4*(n+1))
+		}
+
+		for i := 0; i < n; i++ {
+			v := cellValue(&dt.Rows[start+i], f.index)
+			if v == nil {
+				if !f.nullable {
+					return nil, nil, fmt.Errorf("row %d: column %s does not allow NULL", start+i, f.name)
+				}
+				nulls++
+				if f.kind == columnarString || f.kind == columnarBinary {
+					binary.LittleEndian.PutUint32(values[4*(i+1):], uint32(len(data)))
+				}
+				continue
+			}
+			valid[i/8] |= 1 << (i % 8)
+
+			var err error
+			switch f.kind {
+			case columnarInt64:
+				var x int64
+				x, err = columnarInt64Value(v)
+				binary.LittleEndian.PutUint64(values[8*i:], uint64(x))
+			case columnarFloat64:
+				var x float64
+				x, err = columnarFloat64Value(v)
+				binary.LittleEndian.PutUint64(values[8*i:], math.Float64bits(x))
+			case columnarTime:
+				var t time.Time
+				t, err = columnarTimeValue(v)
+				binary.LittleEndian.PutUint64(values[8*i:], uint64(t.UnixMicro()))
+			case columnarBool:
+				var b bool
+				if b, err = columnarBoolValue(v); b {
+					values[i/8] |= 1 << (i % 8)
+				}
+			case columnarDecimal:
+				u, derr := decimalUnscaled(v, f.precision, f.scale)
+				if err = derr; err == nil {
+					copy(values[16*i:], reverseBytes(decimalBytes(u, 16)))
+				}
+			case columnarBinary:
+				data = append(data, columnarBytesValue(v)...)
+			default:
+				data = append(data, columnarStringValue(v)...)
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("row %d, column %s: %w", start+i, f.name, err)
+			}
+			if f.kind == columnarString || f.kind == columnarBinary {
+				if len(data) > math.MaxInt32 {
+					return nil, nil, fmt.Errorf("column %s has more than 2 GB of data in one batch", f.name)
+				}
+				binary.LittleEndian.PutUint32(values[4*(i+1):], uint32(len(data)))
+			}
+		}
+
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(n))
+		nodes = binary.LittleEndian.AppendUint64(nodes, uint64(nulls))
+		if nulls == 0 {
+			valid = nil
+		}
+		addBuffer(valid)
+		addBuffer(values)
+		if f.kind == columnarString || f.kind == columnarBinary {
+			addBuffer(data)
+		}
+	}
+
+	header := fbTable{
+		fbInt64(int64(n)),
+		fbRef(fbStructs{align: 8, size: 16, data: nodes}),
+		fbRef(fbStructs{align: 8, size: 16, data: buffers}),
+	}
+	return header, body, nil
+}
+
+// writeArrowMessage - writes an encapsulated message and returns the size of its metadata with the prefix
+func writeArrowMessage(w io.Writer, headerType uint8, header fbTable, body []byte) (int, error) {
+	meta := fbBuild(fbTable{
+		fbInt16(arrowMetadataV5),
+		fbUint8(headerType),
+		fbRef(header),
+		fbInt64(int64(len(body))),
+	})
+
+	prefix := binary.LittleEndian.AppendUint32(nil, arrowContinuation)
+	prefix = binary.LittleEndian.AppendUint32(prefix, uint32(len(meta)))
+	if _, err := w.Write(prefix); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(meta); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(body); err != nil {
+		return 0, err
+	}
+	return len(prefix) + len(meta), nil
+}
+
+// countingWriter - counts the bytes written
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// arrowField - a field of an Arrow schema as read
+type arrowField struct {
+	columnarField
+	typeID    uint8
+	bitWidth  int
+	signed    bool
+	unit      int16
+	large     bool
+	timezone  string
+	dateUnit  int16
+	floatSize int16
+}
+
+// ReadArrow - reads a table from an Arrow IPC file or stream. Integer, floating point, utf8, binary, boolean,
+// date, timestamp and decimal128 columns are supported. Integers are read as int64, floats and decimals as
+// float64 and dates and timestamps as UTC times. Dictionary encoded and compressed data is not supported
+func ReadArrow(r io.Reader) (dt *DataTable, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			dt, err = nil, fmt.Errorf("invalid arrow data: %v", rec)
+		}
+	}()
+
+	var messages [][2][]byte
+	if len(data) >= 16 && string(data[:6]) == arrowMagic && string(data[len(data)-6:]) == arrowMagic {
+		// File format: read the record batches listed in the footer
+		flen := int(binary.LittleEndian.Uint32(data[len(data)-10:]))
+		footer := fbRoot(data[len(data)-10-flen : len(data)-10])
+		schema, ok := footer.table(1)
+		if !ok {
+			return nil, errors.New("the arrow file has no schema")
+		}
+
+		n, p := footer.vector(3)
+		for i := 0; i < n; i++ {
+			b := footer.buf[p+24*i:]
+			offset := int(binary.LittleEndian.Uint64(b))
+			metaLen := int(binary.LittleEndian.Uint32(b[8:]))
+			bodyLen := int(binary.LittleEndian.Uint64(b[16:]))
+			meta, _ := arrowMessageMeta(data[offset:])
+			body := data[offset+metaLen : offset+metaLen+bodyLen]
+			messages = append(messages, [2][]byte{meta, body})
+		}
+		return readArrowMessages(schema, messages)
+	}
+
+	// Stream format: a schema message followed by record batches
+	var schema fbReader
+	hasSchema := false
+	for pos := 0; pos < len(data); {
+		meta, size := arrowMessageMeta(data[pos:])
+		if meta == nil {
+			break
+		}
+		msg := fbRoot(meta)
+		bodyLen := int(msg.int64(3, 0))
+		body := data[pos+size : pos+size+bodyLen]
+		pos += size + bodyLen
+
+		switch msg.uint8(1, 0) {
+		case arrowHeaderSchema:
+			if schema, hasSchema = msg.table(2); !hasSchema {
+				return nil, errors.New("the schema message has no schema")
+			}
+		case arrowHeaderRecord:
+			messages = append(messages, [2][]byte{meta, body})
+		default:
+			return nil, fmt.Errorf("unsupported arrow message type %d", msg.uint8(1, 0))
+		}
+	}
+	if !hasSchema {
+		return nil, errors.New("the arrow stream has no schema")
+	}
+	return readArrowMessages(schema, messages)
+}
+
+// arrowMessageMeta - returns the metadata of an encapsulated message and the size of the metadata with its prefix.
+// Returns nil at the end of the stream
+func arrowMessageMeta(b []byte) ([]byte, int) {
+	if len(b) < 8 {
+		return nil, 0
+	}
+	pre := 4
+	size := int(binary.LittleEndian.Uint32(b))
+	if size == arrowContinuation {
+		pre = 8
+		size = int(binary.LittleEndian.Uint32(b[4:]))
+	}
+	if size == 0 {
+		return nil, 0
+	}
+	return b[pre : pre+size], pre + size
+}
+
+// readArrowMessages - builds a table from a schema and record batch messages
+func readArrowMessages(schema fbReader, messages [][2][]byte) (*DataTable, error) {
+	n, p := schema.vector(1)
+	fields := make([]arrowField, n)
+	cols := make([]Column, n)
+	for i := 0; i < n; i++ {
+		ft := schema.vectorTable(p + 4*i)
+		f := arrowField{typeID: ft.uint8(2, 0)}
+		f.name = ft.string(0)
+		f.nullable = ft.bool(1, false)
+		f.index = i
+		if _, ok := ft.table(4); ok {
+			return nil, fmt.Errorf("column %s is dictionary encoded", f.name)
+		}
+
+		typ, _ := ft.table(3)
+		switch f.typeID {
+		case arrowTypeNull, arrowTypeUtf8, arrowTypeLargeUtf8:
+			f.kind = columnarString
+			f.large = f.typeID == arrowTypeLargeUtf8
+		case arrowTypeBinary, arrowTypeLargeBinary:
+			f.kind = columnarBinary
+			f.large = f.typeID == arrowTypeLargeBinary
+		case arrowTypeBool:
+			f.kind = columnarBool
+		case arrowTypeInt:
+			f.kind = columnarInt64
+			f.bitWidth = int(typ.int32(0, 0))
+			f.signed = typ.bool(1, false)
+		case arrowTypeFloat:
+			f.kind = columnarFloat64
+			f.floatSize = typ.int16(0, 0)
+			if f.floatSize == 0 {
+				return nil, fmt.Errorf("column %s has unsupported half precision floats", f.name)
+			}
+		case arrowTypeDecimal:
+			f.kind = columnarDecimal
+			f.precision = int(typ.int32(0, 0))
+			f.scale = int(typ.int32(1, 0))
+			if bw := typ.int32(2, 128); bw != 128 {
+				return nil, fmt.Errorf("column %s has unsupported %d bit decimals", f.name, bw)
+			}
+		case arrowTypeTimestamp:
+			f.kind = columnarTime
+			f.unit = typ.int16(0, 0)
+			f.timezone = typ.string(1)
+		case arrowTypeDate:
+			f.kind = columnarTime
+			f.dateUnit = typ.int16(0, 1)
+		default:
+			return nil, fmt.Errorf("column %s has unsupported arrow type %d", f.name, f.typeID)
+		}
+		fields[i] = f
+		cols[i] = f.column()
+	}
+
+	dt := NewDataTable("")
+	mn, mp := schema.vector(2)
+	for i := 0; i < mn; i++ {
+		kv := schema.vectorTable(mp + 4*i)
+		if kv.string(0) == tableNameKey {
+			dt.Name = kv.string(1)
+		}
+	}
+	dt.AddColumns(cols)
+
+	for _, m := range messages {
+		msg := fbRoot(m[0])
+		if msg.uint8(1, 0) != arrowHeaderRecord {
+			continue
+		}
+		rb, _ := msg.table(2)
+		if _, ok := rb.table(3); ok {
+			return nil, errors.New("compressed record batches are not supported")
+		}
+		if err := dt.readArrowBatch(fields, rb, m[1]); err != nil {
+			return nil, err
+		}
+	}
+	return dt, nil
+}
+
+// readArrowBatch - adds the rows of a record batch
+func (dt *DataTable) readArrowBatch(fields []arrowField, rb fbReader, body []byte) error {
+	length := int(rb.int64(0, 0))
+	nn, np := rb.vector(1)
+	bn, bp := rb.vector(2)
+	if nn != len(fields) {
+		return fmt.Errorf("record batch has %d columns, expected %d", nn, len(fields))
+	}
+
+	nextBuffer := 0
+	buffer := func() []byte {
+		if nextBuffer >= bn {
+			panic("missing buffer")
+		}
+		b := rb.buf[bp+16*nextBuffer:]
+		nextBuffer++
+		offset := int(binary.LittleEndian.Uint64(b))
+		size := int(binary.LittleEndian.Uint64(b[8:]))
+		return body[offset : offset+size]
+	}
+
+	// The buffers of every column are checked against the length before any row is added, so that a corrupt
+	// length cannot allocate more rows than the body holds. Batches with only NULL typed columns have no
+	// buffers to check and are limited to a default batch
+	if length < 0 {
+		return errors.New("invalid record batch length")
+	}
+	type columnBuffers struct {
+		nulls               int
+		valid, values, data []byte
+	}
+	bufs := make([]columnBuffers, len(fields))
+	bounded := false
+	for c, f := range fields {
+		b := &bufs[c]
+		b.nulls = int(binary.LittleEndian.Uint64(rb.buf[np+16*c+8:]))
+		b.valid = buffer()
+		if f.typeID == arrowTypeNull {
+			continue
+		}
+		b.values = buffer()
+		if f.kind == columnarString || f.kind == columnarBinary {
+			b.data = buffer()
+		}
+
+		if b.nulls > 0 && len(b.valid) > 0 && length > len(b.valid)*8 {
+			return fmt.Errorf("column %s has a validity bitmap shorter than %d rows", f.name, length)
+		}
+		if length > 0 && length > arrowMaxRows(f, len(b.values)) {
+			return fmt.Errorf("column %s has fewer values than %d rows", f.name, length)
+		}
+		bounded = true
+	}
+	if !bounded && length > defaultBatchSize {
+		return errors.New("invalid record batch length")
+	}
+
+	first := len(dt.Rows)
+	for i := 0; i < length; i++ {
+		row := dt.NewRow()
+		dt.AddRow(&row)
+	}
+
+	for c, f := range fields {
+		nulls, valid, values, data := bufs[c].nulls, bufs[c].valid, bufs[c].values, bufs[c].data
+
+		for i := 0; i < length; i++ {
+			if f.typeID == arrowTypeNull || (nulls > 0 && len(valid) > 0 && valid[i/8]&(1<<(i%8)) == 0) {
+				continue
+			}
+
+			var v interface{}
+			switch f.kind {
+			case columnarString, columnarBinary:
+				var s, e int
+				if f.large {
+					s, e = int(binary.LittleEndian.Uint64(values[8*i:])), int(binary.LittleEndian.Uint64(values[8*i+8:]))
+				} else {
+					s, e = int(binary.LittleEndian.Uint32(values[4*i:])), int(binary.LittleEndian.Uint32(values[4*i+4:]))
+				}
+				if f.kind == columnarString {
+					v = string(data[s:e])
+				} else {
+					v = bytes.Clone(data[s:e])
+				}
+			case columnarBool:
+				v = values[i/8]&(1<<(i%8)) != 0
+			case columnarInt64:
+				v = arrowInt(values, i, f.bitWidth, f.signed)
+			case columnarFloat64:
+				if f.floatSize == 1 {
+					v = float64(math.Float32frombits(binary.LittleEndian.Uint32(values[4*i:])))
+				} else {
+					v = math.Float64frombits(binary.LittleEndian.Uint64(values[8*i:]))
+				}
+			case columnarDecimal:
+				b := reverseBytes(bytes.Clone(values[16*i : 16*i+16]))
+				v = decimalFloat(decimalFromBytes(b), f.scale)
+			case columnarTime:
+				if f.typeID == arrowTypeDate {
+					if f.dateUnit == 0 {
+						v = time.Unix(int64(int32(binary.LittleEndian.Uint32(values[4*i:])))*86400, 0).UTC()
+					} else {
+						v = time.UnixMilli(int64(binary.LittleEndian.Uint64(values[8*i:]))).UTC()
+					}
+					break
+				}
+				x := int64(binary.LittleEndian.Uint64(values[8*i:]))
+				switch f.unit {
+				case 0:
+					v = time.Unix(x, 0).UTC()
+				case 1:
+					v = time.UnixMilli(x).UTC()
+				case 2:
+					v = time.UnixMicro(x).UTC()
+				default:
+					v = time.Unix(0, x).UTC()
+				}
+			}
+			dt.Rows[first+i].Cells[c].Value = v
+		}
+	}
+	return nil
+}
+
+// arrowMaxRows - returns the number of rows a values buffer of the size holds for a field. String and binary
+// values hold one offset more than the rows
+func arrowMaxRows(f arrowField, size int) int {
+	switch f.kind {
+	case columnarString, columnarBinary:
+		if f.large {
+			return size/8 - 1
+		}
+		return size/4 - 1
+	case columnarBool:
+		return size * 8
+	case columnarInt64:
+		return size / max(f.bitWidth/8, 1)
+	case columnarFloat64:
+		if f.floatSize == 1 {
+			return size / 4
+		}
+		return size / 8
+	case columnarDecimal:
+		return size / 16
+	case columnarTime:
+		if f.typeID == arrowTypeDate && f.dateUnit == 0 {
+			return size / 4
+		}
+		return size / 8
+	}
+	return 0
+}
+
+// arrowInt - returns an integer of the bit width as int64
+func arrowInt(values []byte, i, bitWidth int, signed bool) interface{} {
+	switch bitWidth {
+	case 8:
+		if signed {
+			return int64(int8(values[i]))
+		}
+		return int64(values[i])
+	case 16:
+		u := binary.LittleEndian.Uint16(values[2*i:])
+		if signed {
+			return int64(int16(u))
+		}
+		return int64(u)
+	case 32:
+		u := binary.LittleEndian.Uint32(values[4*i:])
+		if signed {
+			return int64(int32(u))
+		}
+		return int64(u)
+	}
+	return int64(binary.LittleEndian.Uint64(values[8*i:]))
+}
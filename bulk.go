@@ -0,0 +1,135 @@
+package datatable
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BulkInsertOptions - options for BulkInsert
+type BulkInsertOptions struct {
+	Dialect     Dialect           // placeholder and quoting style
+	Columns     map[string]string // maps table columns to target columns. When set, only mapped columns are inserted
+	BatchSize   int               // maximum rows per INSERT statement. Zero fits as many rows as the parameter limit allows
+	MaxParams   int               // parameter limit per statement. Zero uses the limit of the dialect
+	Transaction bool              // run all statements in one transaction that is rolled back on error
+	Progress    func(inserted, total int)
+}
+
+// execer - runs statements on a database or a transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// BulkInsert - inserts the rows of a table into a database table with multi-row INSERT statements
+// and returns the number of rows inserted. Statements are sized to stay under the parameter limit
+func BulkInsert(ctx context.Context, db *sql.DB, tableName string, dt *DataTable, opts BulkInsertOptions) (int64, error) {
+	// Columns to insert in table column order
+	var src []int
+	var target []string
+	if len(opts.Columns) > 0 {
+		for name, to := range opts.Columns {
+			if dt.columnIndex(name) == -1 {
+				return 0, fmt.Errorf("column %s does not exist in table %s", name, dt.Name)
+			}
+			if to == "" {
+				return 0, fmt.Errorf("column %s has an empty mapping", name)
+			}
+		}
+		for i, col := range dt.Columns {
+			for name, to := range opts.Columns {
+				if strings.EqualFold(name, col.Name) {
+					src = append(src, i)
+					target = append(target, to)
+					break
+				}
+			}
+		}
+	} else {
+		for i, col := range dt.Columns {
+			src = append(src, i)
+			target = append(target, col.Name)
+		}
+	}
+	if len(src) == 0 {
+		return 0, errors.New("no columns to insert")
+	}
+
+	maxParams := opts.MaxParams
+	if maxParams <= 0 {
+		maxParams = opts.Dialect.MaxParams()
+	}
+	batch := maxParams / len(src)
+	if opts.BatchSize > 0 && opts.BatchSize < batch {
+		batch = opts.BatchSize
+	}
+	if batch == 0 {
+		return 0, fmt.Errorf("%d columns exceed the limit of %d parameters", len(src), maxParams)
+	}
+
+	quoted := make([]string, len(target))
+	for i, t := range target {
+		quoted[i] = opts.Dialect.QuoteIdent(t)
+	}
+	prefix := "INSERT INTO " + opts.Dialect.QuoteIdent(tableName) + " (" + strings.Join(quoted, ", ") + ") VALUES "
+
+	var ex execer = db
+	var tx *sql.Tx
+	if opts.Transaction {
+		var err error
+		if tx, err = db.BeginTx(ctx, nil); err != nil {
+			return 0, err
+		}
+		ex = tx
+	}
+
+	total := len(dt.Rows)
+	var inserted int64
+	for start := 0; start < total; start += batch {
+		end := min(start+batch, total)
+
+		var sb strings.Builder
+		sb.WriteString(prefix)
+		args := make([]interface{}, 0, (end-start)*len(src))
+		for r := start; r < end; r++ {
+			if r > start {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('(')
+			for j, c := range src {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				var v interface{}
+				if c < len(dt.Rows[r].Cells) {
+					v = dt.Rows[r].Cells[c].Value
+				}
+				args = append(args, v)
+				sb.WriteString(opts.Dialect.Placeholder(len(args)))
+			}
+			sb.WriteByte(')')
+		}
+
+		if _, err := ex.ExecContext(ctx, sb.String(), args...); err != nil {
+			if tx != nil {
+				tx.Rollback()
+				inserted = 0
+			}
+			return inserted, err
+		}
+
+		inserted += int64(end - start)
+		if opts.Progress != nil {
+			opts.Progress(int(inserted), total)
+		}
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	return inserted, nil
+}
@@ -0,0 +1,88 @@
+package datatable
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/eaglebush/datatable/datatabletest"
+)
+
+func TestBulkInsert(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	dt.AddColumn("Code", reflect.TypeOf(""), 12, "")
+	for i := 0; i < 5; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		r.Cells[1].Value = "Code" + strconv.Itoa(i)
+		dt.AddRow(&r)
+	}
+	db := datatabletest.Open()
+	defer db.Close()
+
+	var progress []int
+	n, err := BulkInsert(context.Background(), db, "dbo.Staging", dt, BulkInsertOptions{
+		Dialect:     DialectSQLServer,
+		MaxParams:   5,
+		Transaction: true,
+		Progress:    func(inserted, total int) { progress = append(progress, inserted) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatalf("expected 5 rows inserted, got %d", n)
+	}
+
+	execs := datatabletest.Execs(db)
+	if len(execs) != 5 || execs[0].Query != "BEGIN" || execs[4].Query != "COMMIT" {
+		t.Fatalf("unexpected statements %v", execs)
+	}
+
+	// Two columns and five parameters fit two rows per statement. AddColumn lower cases the names
+	want := "INSERT INTO [dbo].[Staging] ([id], [code]) VALUES (@p1, @p2), (@p3, @p4)"
+	if execs[1].Query != want {
+		t.Fatalf("unexpected statement\n%s\nexpected\n%s", execs[1].Query, want)
+	}
+	if len(execs[3].Args) != 2 || execs[3].Args[0] != int64(4) || execs[3].Args[1] != "Code4" {
+		t.Fatalf("unexpected arguments %v", execs[3].Args)
+	}
+	if len(progress) != 3 || progress[2] != 5 {
+		t.Fatalf("unexpected progress %v", progress)
+	}
+}
+
+func TestBulkInsertColumnMapping(t *testing.T) {
+	// Only the mapped column is inserted
+	dt := NewDataTable("Items")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	dt.AddColumn("Code", reflect.TypeOf(""), 12, "")
+	for i := 0; i < 3; i++ {
+		r := dt.NewRow()
+		dt.AddRow(&r)
+	}
+	db := datatabletest.Open()
+	defer db.Close()
+
+	_, err := BulkInsert(context.Background(), db, "staging", dt, BulkInsertOptions{
+		Dialect:   DialectPostgres,
+		Columns:   map[string]string{"code": "item_code"},
+		BatchSize: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	execs := datatabletest.Execs(db)
+	if len(execs) != 1 || execs[0].Query != `INSERT INTO "staging" ("item_code") VALUES ($1), ($2), ($3)` {
+		t.Fatalf("unexpected statements %v", execs)
+	}
+
+	_, err = BulkInsert(context.Background(), db, "staging", dt, BulkInsertOptions{Columns: map[string]string{"nope": "x"}})
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected an error for a missing column, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package datatable
+
+import "strings"
+
+// Clone - returns a new empty data table with the same name and columns
+func (dt *DataTable) Clone() *DataTable {
+	c := NewDataTable(dt.Name)
+	c.Columns = make([]Column, len(dt.Columns))
+	copy(c.Columns, dt.Columns)
+	c.ColumnCount = len(c.Columns)
+	return c
+}
+
+// Copy - returns a new data table with the same columns and a deep copy of every row.
+// Changes to the copy do not affect the source table
+func (dt *DataTable) Copy() *DataTable {
+	c := dt.Clone()
+	c.Rows = make([]Row, len(dt.Rows))
+	for i := range dt.Rows {
+		c.Rows[i] = copyRow(&dt.Rows[i])
+	}
+	c.RowCount = len(c.Rows)
+	return c
+}
+
+// ImportRow - adds a copy of a row from another table. Cells are matched to the columns of this table
+// by name, ignoring case. Columns missing from the row are left null and extra cells are ignored
+func (dt *DataTable) ImportRow(r *Row) {
+	nr := dt.NewRow()
+	for i := range nr.Cells {
+		idx := r.cellIndex(nr.Cells[i].ColumnName)
+		if idx == -1 {
+			continue
+		}
+		nr.Cells[i].Value = copyValue(r.Cells[idx].Value)
+	}
+	dt.AddRow(&nr)
+}
+
+// copyRow - returns a deep copy of a row with its own cells and column name index
+func copyRow(src *Row) Row {
+	r := Row{
+		Cells:                   make([]Cell, len(src.Cells)),
+		ColumnCount:             src.ColumnCount,
+		currentColumnNamesIndex: make(map[string]int, len(src.Cells)),
+	}
+	for i, c := range src.Cells {
+		c.Value = copyValue(c.Value)
+		r.Cells[i] = c
+		r.currentColumnNamesIndex[strings.ToLower(c.ColumnName)] = i
+	}
+	return r
+}
+
+// copyValue - copies values that would otherwise be shared between cells
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		if v == nil {
+			return v
+		}
+		b := make([]byte, len(v))
+		copy(b, v)
+		return b
+	}
+	return value
+}
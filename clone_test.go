@@ -0,0 +1,72 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCloneAndCopy(t *testing.T) {
+	dt := NewDataTable("Source")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	dt.AddColumn("Photo", reflect.TypeOf([]byte{}), 0, "IMAGE")
+	dt.AddColumn("Created", reflect.TypeOf(time.Time{}), 0, "")
+	r := dt.NewRow()
+	r.Cells[0].Value = 1
+	r.Cells[1].Value = []byte{1, 2, 3}
+	r.Cells[2].Value = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	dt.AddRow(&r)
+
+	cl := dt.Clone()
+	if cl.ColumnCount != 3 || cl.RowCount != 0 || len(cl.Rows) != 0 {
+		t.Fatal("expected Clone to copy the columns only")
+	}
+	cl.Columns[0].Name = "Changed"
+	if dt.Columns[0].Name == "Changed" {
+		t.Fatal("expected Clone to have its own columns")
+	}
+
+	cp := dt.Copy()
+	if cp.RowCount != 1 || cp.Rows[0].ValueInt("ID") != 1 {
+		t.Fatal("expected Copy to copy the rows")
+	}
+
+	cp.Rows[0].Cells[0].Value = 2
+	cp.Rows[0].Cells[1].Value.([]byte)[0] = 9
+	if dt.Rows[0].ValueInt("ID") != 1 || dt.Rows[0].Cells[1].Value.([]byte)[0] != 1 {
+		t.Fatal("expected changes to the copy to not affect the source")
+	}
+	if !cp.Rows[0].ValueTime("Created").Equal(dt.Rows[0].ValueTime("Created")) {
+		t.Fatal("expected the time value to be copied")
+	}
+}
+
+func TestImportRow(t *testing.T) {
+	src := NewDataTable("Source")
+	src.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	src.AddColumn("Photo", reflect.TypeOf([]byte{}), 0, "IMAGE")
+	sr := src.NewRow()
+	sr.Cells[0].Value = 1
+	sr.Cells[1].Value = []byte{1, 2, 3}
+	src.AddRow(&sr)
+
+	dst := NewDataTable("Target")
+	dst.AddColumn("Extra", reflect.TypeOf(""), 10, "")
+	dst.AddColumn("id", reflect.TypeOf(0), 0, "")
+	dst.AddColumn("PHOTO", reflect.TypeOf([]byte{}), 0, "IMAGE")
+
+	dst.ImportRow(&src.Rows[0])
+	if dst.RowCount != 1 {
+		t.Fatal("expected one imported row")
+	}
+
+	r := dst.Rows[0]
+	if r.Value("Extra") != nil || r.ValueInt("ID") != 1 {
+		t.Fatalf("unexpected imported values %v, %v", r.Value("Extra"), r.Value("ID"))
+	}
+
+	r.Cells[2].Value.([]byte)[0] = 9
+	if src.Rows[0].Cells[1].Value.([]byte)[0] != 1 {
+		t.Fatal("expected the imported bytes to be copied")
+	}
+}
@@ -0,0 +1,280 @@
+package datatable
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columnarKind - the logical type of a column in the Arrow and Parquet formats
+type columnarKind int
+
+const (
+	columnarString columnarKind = iota
+	columnarInt64
+	columnarFloat64
+	columnarBool
+	columnarTime
+	columnarBinary
+	columnarDecimal
+)
+
+// Default precision and scale of DECIMAL columns that do not give them
+const (
+	defaultDecimalPrecision = 38
+	defaultDecimalScale     = 9
+)
+
+// columnarField - a column of a table as written to a columnar format
+type columnarField struct {
+	name      string
+	kind      columnarKind
+	nullable  bool
+	precision int
+	scale     int
+	index     int
+}
+
+var decimalTypeRe = regexp.MustCompile(`^\s*(?:DECIMAL|NUMERIC)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?\s*$`)
+
+// columnarFields - returns the columnar fields of the table columns
+func (dt *DataTable) columnarFields() ([]columnarField, error) {
+	fields := make([]columnarField, len(dt.Columns))
+	for i, col := range dt.Columns {
+		f := columnarField{name: col.Name, nullable: !col.NotNull && !col.PrimaryKey, index: i}
+		f.kind, f.precision, f.scale = columnarKindOf(col)
+		if f.kind == columnarDecimal && (f.precision < 1 || f.precision > 38 || f.scale < 0 || f.scale > f.precision) {
+			return nil, fmt.Errorf("column %s has an invalid decimal type %s", col.Name, col.DBType)
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
+
+// columnarKindOf - returns the columnar kind of a column, and the precision and scale of decimal columns.
+// DECIMAL or NUMERIC without a precision uses a precision of 38 and a scale of 9
+func columnarKindOf(col Column) (columnarKind, int, int) {
+	dbType := strings.ToUpper(col.DBType)
+	switch dbType {
+	case "MONEY":
+		return columnarDecimal, 19, 4
+	case "SMALLMONEY":
+		return columnarDecimal, 10, 4
+	}
+	if m := decimalTypeRe.FindStringSubmatch(dbType); m != nil {
+		p, s := defaultDecimalPrecision, defaultDecimalScale
+		if m[1] != "" {
+			p, _ = strconv.Atoi(m[1])
+			s = 0
+			if m[2] != "" {
+				s, _ = strconv.Atoi(m[2])
+			}
+		}
+		return columnarDecimal, p, s
+	}
+
+	if col.Type == nil {
+		return columnarString, 0, 0
+	}
+	switch col.Type {
+	case reflect.TypeOf(time.Time{}):
+		return columnarTime, 0, 0
+	case reflect.TypeOf([]byte{}):
+		return columnarBinary, 0, 0
+	}
+	switch col.Type.Kind() {
+	case reflect.Bool:
+		return columnarBool, 0, 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return columnarInt64, 0, 0
+	case reflect.Float32, reflect.Float64:
+		return columnarFloat64, 0, 0
+	}
+	return columnarString, 0, 0
+}
+
+// column - returns the table column of a field read from a columnar format
+func (f columnarField) column() Column {
+	col := Column{Name: f.name, NotNull: !f.nullable}
+	switch f.kind {
+	case columnarInt64:
+		col.Type = reflect.TypeOf(int64(0))
+	case columnarFloat64:
+		col.Type = reflect.TypeOf(0.0)
+	case columnarBool:
+		col.Type = reflect.TypeOf(false)
+	case columnarTime:
+		col.Type = reflect.TypeOf(time.Time{})
+	case columnarBinary:
+		col.Type = reflect.TypeOf([]byte{})
+	case columnarDecimal:
+		col.Type = reflect.TypeOf(0.0)
+		col.DBType = fmt.Sprintf("DECIMAL(%d,%d)", f.precision, f.scale)
+	default:
+		col.Type = reflect.TypeOf("")
+	}
+	return col
+}
+
+// columnarInt64Value - converts a cell value to int64
+func columnarInt64Value(v interface{}) (int64, error) {
+	switch x := normalizeValue(v).(type) {
+	case int64:
+		return x, nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(x), 10, 64)
+	case bool:
+		if x {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("cannot convert %T %v to an integer", v, v)
+}
+
+// columnarFloat64Value - converts a cell value to float64
+func columnarFloat64Value(v interface{}) (float64, error) {
+	if f, ok := toFloat64(v); ok {
+		return f, nil
+	}
+	switch x := v.(type) {
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(x), 64)
+	case []byte:
+		return strconv.ParseFloat(strings.TrimSpace(string(x)), 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T %v to a number", v, v)
+}
+
+// columnarBoolValue - converts a cell value to bool
+func columnarBoolValue(v interface{}) (bool, error) {
+	switch x := normalizeValue(v).(type) {
+	case bool:
+		return x, nil
+	case int64:
+		return x != 0, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(x))
+	}
+	return false, fmt.Errorf("cannot convert %T %v to a boolean", v, v)
+}
+
+// columnarTimeValue - converts a cell value to time.Time
+func columnarTimeValue(v interface{}) (time.Time, error) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, nil
+	case *time.Time:
+		if x != nil {
+			return *x, nil
+		}
+	case string:
+		return time.Parse(time.RFC3339Nano, strings.TrimSpace(x))
+	case []byte:
+		return time.Parse(time.RFC3339Nano, strings.TrimSpace(string(x)))
+	}
+	return time.Time{}, fmt.Errorf("cannot convert %T %v to a time", v, v)
+}
+
+// columnarStringValue - converts a cell value to string
+func columnarStringValue(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+// columnarBytesValue - converts a cell value to []byte
+func columnarBytesValue(v interface{}) []byte {
+	switch x := v.(type) {
+	case []byte:
+		return x
+	case string:
+		return []byte(x)
+	}
+	return []byte(fmt.Sprint(v))
+}
+
+// decimalUnscaled - converts a cell value to the unscaled integer of a decimal with the precision and scale.
+// Values are rounded half away from zero to the scale
+func decimalUnscaled(v interface{}, precision, scale int) (*big.Int, error) {
+	r := new(big.Rat)
+	switch x := normalizeValue(v).(type) {
+	case int64:
+		r.SetInt64(x)
+	case uint64:
+		r.SetInt(new(big.Int).SetUint64(x))
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return nil, fmt.Errorf("cannot convert %v to a decimal", x)
+		}
+		r.SetString(strconv.FormatFloat(x, 'f', -1, 64))
+	case string:
+		if _, ok := r.SetString(strings.TrimSpace(x)); !ok {
+			return nil, fmt.Errorf("cannot convert %q to a decimal", x)
+		}
+	default:
+		return nil, fmt.Errorf("cannot convert %T %v to a decimal", v, v)
+	}
+
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	num := new(big.Int).Mul(r.Num(), pow)
+	q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if m.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(m), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(m.Sign())))
+	}
+
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)
+	if new(big.Int).Abs(q).Cmp(limit) >= 0 {
+		return nil, fmt.Errorf("%v does not fit DECIMAL(%d,%d)", v, precision, scale)
+	}
+	return q, nil
+}
+
+// decimalFloat - returns the float64 value of an unscaled decimal
+func decimalFloat(unscaled *big.Int, scale int) float64 {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	f, _ := new(big.Rat).SetFrac(unscaled, pow).Float64()
+	return f
+}
+
+// decimalBytes - returns the two's complement of an integer in n big endian bytes
+func decimalBytes(v *big.Int, n int) []byte {
+	b := make([]byte, n)
+	if v.Sign() >= 0 {
+		v.FillBytes(b)
+		return b
+	}
+	// Two's complement of a negative value is 2^(8n) + v
+	t := new(big.Int).Lsh(big.NewInt(1), uint(8*n))
+	t.Add(t, v).FillBytes(b)
+	return b
+}
+
+// decimalFromBytes - returns the integer of a big endian two's complement
+func decimalFromBytes(b []byte) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+	return v
+}
+
+// reverseBytes - reverses bytes in place, converting between little and big endian
+func reverseBytes(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
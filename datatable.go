@@ -12,10 +12,12 @@ import (
 
 //Column - a column in the data table
 type Column struct {
-	Name   string
-	Type   reflect.Type
-	DBType string
-	Length int64
+	Name       string
+	Type       reflect.Type
+	DBType     string
+	Length     int64
+	PrimaryKey bool
+	NotNull    bool
 }
 
 //Row - a row in the data table
@@ -92,6 +94,46 @@ func (dt *DataTable) AddColumns(newcolumns []Column) {
 	dt.ColumnCount = len(dt.Columns)
 }
 
+// columnIndex - gets the index of a column by its name, ignoring case. Returns -1 if the column does not exist
+func (dt *DataTable) columnIndex(name string) int {
+	name = strings.ToLower(name)
+	for i, col := range dt.Columns {
+		if strings.ToLower(col.Name) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// PrimaryKey - returns the names of the primary key columns in column order
+func (dt *DataTable) PrimaryKey() []string {
+	var keys []string
+	for _, col := range dt.Columns {
+		if col.PrimaryKey {
+			keys = append(keys, col.Name)
+		}
+	}
+	return keys
+}
+
+// SetPrimaryKey - marks the named columns as the primary key. Columns not named are unmarked
+func (dt *DataTable) SetPrimaryKey(names ...string) error {
+	idx := make([]int, len(names))
+	for i, name := range names {
+		if idx[i] = dt.columnIndex(name); idx[i] == -1 {
+			return fmt.Errorf("column %s does not exist", name)
+		}
+	}
+
+	for i := range dt.Columns {
+		dt.Columns[i].PrimaryKey = false
+	}
+	for _, i := range idx {
+		dt.Columns[i].PrimaryKey = true
+	}
+	return nil
+}
+
 // AddRow - add a row to the current rows
 /*
 func (dt *DataTable) AddRow(row Row) {
@@ -147,6 +189,116 @@ func (dt *DataTable) AddRows(rows []Row) {
 	rows = nil
 }
 
+// InsertAt - inserts a row at the index and shifts the rows after it. An index equal to RowCount appends the row.
+// Returns false if the index is out of range
+func (dt *DataTable) InsertAt(index int, row *Row) bool {
+	if index < 0 || index > len(dt.Rows) {
+		return false
+	}
+
+	var r Row
+	r.ColumnCount = row.ColumnCount
+	r.Cells = append(r.Cells, row.Cells...)
+	r.currentColumnNamesIndex = make(map[string]int)
+	for i := range r.Cells {
+		r.Cells[i].ColumnIndex = i
+		r.currentColumnNamesIndex[strings.ToLower(r.Cells[i].ColumnName)] = i
+	}
+
+	dt.Rows = append(dt.Rows, Row{})
+	copy(dt.Rows[index+1:], dt.Rows[index:])
+	dt.Rows[index] = r
+	dt.RowCount = len(dt.Rows)
+	dt.reindexRows(index, dt.RowCount)
+	return true
+}
+
+// RemoveAt - removes the row at the index. Returns false if the index is out of range
+func (dt *DataTable) RemoveAt(index int) bool {
+	if index < 0 || index >= len(dt.Rows) {
+		return false
+	}
+
+	copy(dt.Rows[index:], dt.Rows[index+1:])
+	dt.Rows[len(dt.Rows)-1] = Row{}
+	dt.Rows = dt.Rows[:len(dt.Rows)-1]
+	dt.RowCount = len(dt.Rows)
+	dt.reindexRows(index, dt.RowCount)
+	return true
+}
+
+// Remove - removes the row pointed to by row. The pointer must come from this table, as returned by All or a Cursor.
+// Returns false if the row is not in the table
+func (dt *DataTable) Remove(row *Row) bool {
+	for i := range dt.Rows {
+		if &dt.Rows[i] == row {
+			return dt.RemoveAt(i)
+		}
+	}
+	return false
+}
+
+// RemoveWhere - removes all rows where pred returns true and returns the number of rows removed
+func (dt *DataTable) RemoveWhere(pred func(row *Row) bool) int {
+	n := 0
+	for i := range dt.Rows {
+		if pred(&dt.Rows[i]) {
+			continue
+		}
+		if n != i {
+			dt.Rows[n] = dt.Rows[i]
+		}
+		n++
+	}
+
+	removed := len(dt.Rows) - n
+	for i := n; i < len(dt.Rows); i++ {
+		dt.Rows[i] = Row{}
+	}
+	dt.Rows = dt.Rows[:n]
+	dt.RowCount = n
+	dt.reindexRows(0, n)
+	return removed
+}
+
+// MoveRow - moves the row at index from to index to, shifting the rows in between.
+// Returns false if either index is out of range
+func (dt *DataTable) MoveRow(from, to int) bool {
+	if from < 0 || from >= len(dt.Rows) || to < 0 || to >= len(dt.Rows) {
+		return false
+	}
+	if from == to {
+		return true
+	}
+
+	r := dt.Rows[from]
+	if from < to {
+		copy(dt.Rows[from:to], dt.Rows[from+1:to+1])
+		dt.Rows[to] = r
+		dt.reindexRows(from, to+1)
+	} else {
+		copy(dt.Rows[to+1:from+1], dt.Rows[to:from])
+		dt.Rows[to] = r
+		dt.reindexRows(to, from+1)
+	}
+	return true
+}
+
+// Clear - removes all rows but keeps the columns
+func (dt *DataTable) Clear() {
+	dt.Rows = nil
+	dt.RowCount = 0
+}
+
+// reindexRows - sets the RowIndex of the cells of the rows from start up to end
+func (dt *DataTable) reindexRows(start, end int) {
+	for f := start; f < end; f++ {
+		for g := range dt.Rows[f].Cells {
+			dt.Rows[f].Cells[g].RowIndex = f
+		}
+	}
+}
+
 // NewRow - returns a new row based on column structure
 func (dt *DataTable) NewRow() Row {
 	colcnt := len(dt.Columns)
@@ -164,11 +316,20 @@ func (dt *DataTable) NewRow() Row {
 
 // resizeCells for AddColumn and AddColumns
 func (dt *DataTable) resizeCells() {
-	for i, r := range dt.Rows {
+	last := len(dt.Columns) - 1
+	col := dt.Columns[last]
+	for i := range dt.Rows {
+		r := &dt.Rows[i]
 		r.Cells = append(r.Cells, Cell{
-			ColumnIndex: len(dt.Columns) - 1,
-			RowIndex:    i,
-			Value:       nil})
+			ColumnName:   col.Name,
+			ColumnIndex:  last,
+			RowIndex:     i,
+			DBColumnType: col.DBType,
+			Value:        nil})
+		r.ColumnCount = len(r.Cells)
+		if r.currentColumnNamesIndex != nil {
+			r.currentColumnNamesIndex[strings.ToLower(col.Name)] = last
+		}
 	}
 }
 
@@ -226,9 +387,10 @@ func (rw *Row) Next() bool {
 	for i := 0; i < ccnt; i++ {
 		v := rw.tmpRows[i].(*interface{})
 		if *v != nil {
-			switch rw.Cells[i].DBColumnType {
-			case "DECIMAL":
-				f, _ := strconv.ParseFloat(string((*v).([]uint8)), 64)
+			b, isbytes := (*v).([]uint8)
+			switch {
+			case isbytes && rw.Cells[i].DBColumnType == "DECIMAL":
+				f, _ := strconv.ParseFloat(string(b), 64)
 				rw.ResultRows[i] = &f
 				rw.Cells[i].Value = f
 			default:
@@ -267,23 +429,7 @@ func (rw *Row) Value(index interface{}) interface{} {
 	}
 
 	if tname == "string" {
-		var ok bool
-		idx, ok = rw.currentColumnNamesIndex[index.(string)]
-		if !ok {
-			idx = -1
-			if rw.currentColumnNamesIndex == nil {
-				rw.currentColumnNamesIndex = make(map[string]int)
-			}
-			kname := strings.ToLower(index.(string))
-			for i, c := range rw.Cells {
-				if strings.ToLower(c.ColumnName) == kname {
-					idx = i
-
-					rw.currentColumnNamesIndex[kname] = i
-					break
-				}
-			}
-		}
+		idx = rw.cellIndex(index.(string))
 	}
 
 	if idx != -1 {
@@ -307,6 +453,22 @@ func (rw *Row) Value(index interface{}) interface{} {
 	return nil
 }
 
+// cellIndex - gets the cell index of a column name. The lookup never writes to the row so it is safe for concurrent readers
+func (rw *Row) cellIndex(name string) int {
+	kname := strings.ToLower(name)
+	if idx, ok := rw.currentColumnNamesIndex[kname]; ok {
+		return idx
+	}
+
+	for i := range rw.Cells {
+		if strings.ToLower(rw.Cells[i].ColumnName) == kname {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // ValueByOrdinal - get values by ordinal index
 func (rw *Row) ValueByOrdinal(index *int) interface{} {
 	if *index > len(rw.Cells) {
@@ -330,25 +492,7 @@ func (rw *Row) ValueByOrdinal(index *int) interface{} {
 
 // ValueByName - get values by column name index
 func (rw *Row) ValueByName(index *string) interface{} {
-	idx := -1
-
-	kname := strings.ToLower(*index)
-	var ok bool
-	idx, ok = rw.currentColumnNamesIndex[kname]
-	if !ok {
-		if rw.currentColumnNamesIndex == nil {
-			rw.currentColumnNamesIndex = make(map[string]int)
-		}
-		idx = -1
-		for i := range rw.Cells {
-			if strings.ToLower(rw.Cells[i].ColumnName) == kname {
-				idx = i
-				rw.currentColumnNamesIndex[kname] = i
-				break
-			}
-		}
-	}
-
+	idx := rw.cellIndex(*index)
 	if idx == -1 {
 		return nil
 	}
@@ -1,10 +1,15 @@
 package datatable
 
 import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"log"
 	"reflect"
 	"strconv"
 	"testing"
+
+	"github.com/eaglebush/datatable/datatabletest"
 )
 
 func TestRowAdding(t *testing.T) {
@@ -244,3 +249,139 @@ func BenchmarkBulkRowAddingRange(b *testing.B) {
 	*/
 
 }
+
+func checkRowIndexes(t *testing.T, dt *DataTable) {
+	t.Helper()
+	if dt.RowCount != len(dt.Rows) {
+		t.Fatalf("RowCount %d does not match %d rows", dt.RowCount, len(dt.Rows))
+	}
+	for i, rw := range dt.Rows {
+		for _, co := range rw.Cells {
+			if co.RowIndex != i {
+				t.Fatalf("row %d has a cell with RowIndex %d", i, co.RowIndex)
+			}
+		}
+	}
+}
+
+func rowIDs(dt *DataTable) []int {
+	var ids []int
+	for _, rw := range dt.Rows {
+		ids = append(ids, rw.ValueInt("ID"))
+	}
+	return ids
+}
+
+func TestRowRemoveInsertMove(t *testing.T) {
+	dt := NewDataTable("Simon")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+
+	for i := 0; i < 5; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		dt.AddRow(&r)
+	}
+
+	if !dt.RemoveAt(1) || dt.RemoveAt(10) {
+		t.Fatal("unexpected RemoveAt result")
+	}
+	checkRowIndexes(t, dt)
+
+	if !dt.Remove(&dt.Rows[0]) {
+		t.Fatal("expected Remove to find the row")
+	}
+	checkRowIndexes(t, dt)
+
+	r := dt.NewRow()
+	r.Cells[0].Value = 9
+	if !dt.InsertAt(1, &r) || dt.InsertAt(-1, &r) {
+		t.Fatal("unexpected InsertAt result")
+	}
+	checkRowIndexes(t, dt)
+
+	if !dt.MoveRow(0, 3) {
+		t.Fatal("expected MoveRow to succeed")
+	}
+	checkRowIndexes(t, dt)
+
+	if got := fmt.Sprint(rowIDs(dt)); got != "[9 3 4 2]" {
+		t.Fatalf("unexpected row order %s", got)
+	}
+
+	if n := dt.RemoveWhere(func(row *Row) bool { return row.ValueInt("ID") > 3 }); n != 2 {
+		t.Fatalf("expected 2 rows removed, got %d", n)
+	}
+	checkRowIndexes(t, dt)
+
+	dt.Clear()
+	if dt.RowCount != 0 || len(dt.Rows) != 0 || dt.ColumnCount != 1 {
+		t.Fatal("expected Clear to remove rows only")
+	}
+}
+
+func TestRowNext(t *testing.T) {
+	rows := datatabletest.NewRows(t, datatabletest.Script{
+		Columns: []datatabletest.Column{
+			{Name: "ID", DBType: "INT"},
+			{Name: "Price", DBType: "DECIMAL"},
+			{Name: "Name", DBType: "VARCHAR"},
+			{Name: "Photo", DBType: "IMAGE"},
+		},
+		Rows: [][]driver.Value{
+			{int64(1), []byte("12.50"), []byte("Apple"), []byte{1, 2}},
+			{int64(2), nil, nil, nil},
+		},
+	})
+
+	var r Row
+	r.SetSQLRow(rows)
+	defer r.Close()
+
+	if !r.Next() {
+		t.Fatal("expected a first row")
+	}
+	if r.ValueInt64("id") != 1 || r.ValueFloat64("Price") != 12.5 || r.ValueString("Name") != "Apple" {
+		t.Fatalf("unexpected first row %v", r.Cells)
+	}
+	if b, ok := r.Value("Photo").([]byte); !ok || len(b) != 2 {
+		t.Fatalf("expected IMAGE bytes, got %v", r.Value("Photo"))
+	}
+	if r.Cells[1].DBColumnType != "DECIMAL" {
+		t.Fatalf("expected the DB column type to be set, got %s", r.Cells[1].DBColumnType)
+	}
+
+	if !r.Next() {
+		t.Fatal("expected a second row")
+	}
+	if r.ValuePtrFloat64("Price") != nil || r.ValuePtrString("Name") != nil || r.Value("Photo") != nil {
+		t.Fatalf("expected NULL values, got %v", r.Cells)
+	}
+
+	if r.Next() {
+		t.Fatal("expected no more rows")
+	}
+}
+
+func TestRowNextError(t *testing.T) {
+	rows := datatabletest.NewRows(t, datatabletest.Script{
+		Columns:  []datatabletest.Column{{Name: "ID", DBType: "INT"}},
+		Rows:     [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}},
+		Err:      errors.New("connection lost"),
+		ErrAfter: 2,
+	})
+
+	var r Row
+	r.SetSQLRow(rows)
+	defer r.Close()
+
+	n := 0
+	for r.Next() {
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 rows before the error, got %d", n)
+	}
+	if rows.Err() == nil || rows.Err().Error() != "connection lost" {
+		t.Fatalf("expected the scripted error, got %v", rows.Err())
+	}
+}
@@ -0,0 +1,291 @@
+// Package datatabletest provides a scripted database/sql driver for testing code that reads
+// query results, such as Row.Next, without a database.
+package datatabletest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// DriverName - the name of the scripted driver in database/sql
+const DriverName = "datatabletest"
+
+// Column - a scripted result column
+type Column struct {
+	Name     string
+	DBType   string       // returned by ColumnType.DatabaseTypeName
+	ScanType reflect.Type // returned by ColumnType.ScanType. Defaults to interface{}
+	Length   int64        // returned by ColumnType.Length when greater than zero
+	Nullable *bool        // returned by ColumnType.Nullable when set
+}
+
+// Script - the result of one query
+type Script struct {
+	Columns  []Column
+	Rows     [][]driver.Value
+	Err      error // returned by Next after ErrAfter rows have been read
+	ErrAfter int
+	QueryErr error // returned by the query instead of a result
+}
+
+// Exec - a statement run on a database returned by Open. Transactions are recorded as
+// statements named BEGIN, COMMIT and ROLLBACK
+type Exec struct {
+	Query string
+	Args  []driver.Value
+}
+
+type source struct {
+	mu      sync.Mutex
+	scripts []Script
+	next    int
+	execs   []Exec
+}
+
+var (
+	sourcesMu sync.Mutex
+	sources   = make(map[string]*source)
+	databases = make(map[*sql.DB]*source)
+	lastID    atomic.Int64
+)
+
+func init() {
+	sql.Register(DriverName, &scriptDriver{})
+}
+
+// Open - returns a database whose queries return the scripts in order, whatever the query text.
+// Queries after the last script fail. Closing the database releases the scripts
+func Open(scripts ...Script) *sql.DB {
+	c := &connector{dsn: fmt.Sprintf("script%d", lastID.Add(1)), src: &source{scripts: scripts}}
+	c.db = sql.OpenDB(c)
+
+	sourcesMu.Lock()
+	sources[c.dsn] = c.src
+	databases[c.db] = c.src
+	sourcesMu.Unlock()
+	return c.db
+}
+
+// connector - connects a database returned by Open to its source. database/sql closes it with the database
+type connector struct {
+	dsn string
+	src *source
+	db  *sql.DB
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &scriptConn{src: c.src}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return &scriptDriver{}
+}
+
+// Close - removes the source so that databases that are no longer used do not keep their scripts
+func (c *connector) Close() error {
+	sourcesMu.Lock()
+	delete(sources, c.dsn)
+	delete(databases, c.db)
+	sourcesMu.Unlock()
+	return nil
+}
+
+// Execs - returns the statements run with Exec on a database returned by Open, in order
+func Execs(db *sql.DB) []Exec {
+	sourcesMu.Lock()
+	src, ok := databases[db]
+	sourcesMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	return append([]Exec(nil), src.execs...)
+}
+
+// NewRows - returns the rows of a script. The rows and their database are closed when the test ends
+func NewRows(tb testing.TB, s Script) *sql.Rows {
+	tb.Helper()
+
+	db := Open(s)
+	tb.Cleanup(func() {
+		db.Close()
+	})
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		tb.Fatalf("datatabletest: %v", err)
+	}
+	tb.Cleanup(func() {
+		rows.Close()
+	})
+	return rows
+}
+
+type scriptDriver struct{}
+
+func (d *scriptDriver) Open(name string) (driver.Conn, error) {
+	sourcesMu.Lock()
+	src, ok := sources[name]
+	sourcesMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("datatabletest: unknown data source %s", name)
+	}
+	return &scriptConn{src: src}, nil
+}
+
+type scriptConn struct {
+	src *source
+}
+
+func (c *scriptConn) Prepare(query string) (driver.Stmt, error) {
+	return &scriptStmt{conn: c, query: query}, nil
+}
+
+func (c *scriptConn) Close() error {
+	return nil
+}
+
+func (c *scriptConn) Begin() (driver.Tx, error) {
+	c.record("BEGIN", nil)
+	return scriptTx{conn: c}, nil
+}
+
+// ExecContext - records the statement. No rows are reported as affected
+func (c *scriptConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	vals := make([]driver.Value, len(args))
+	for i, a := range args {
+		vals[i] = a.Value
+	}
+	c.record(query, vals)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *scriptConn) record(query string, args []driver.Value) {
+	c.src.mu.Lock()
+	defer c.src.mu.Unlock()
+	c.src.execs = append(c.src.execs, Exec{Query: query, Args: args})
+}
+
+type scriptTx struct {
+	conn *scriptConn
+}
+
+func (t scriptTx) Commit() error {
+	t.conn.record("COMMIT", nil)
+	return nil
+}
+
+func (t scriptTx) Rollback() error {
+	t.conn.record("ROLLBACK", nil)
+	return nil
+}
+
+func (c *scriptConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.src.mu.Lock()
+	defer c.src.mu.Unlock()
+
+	if c.src.next >= len(c.src.scripts) {
+		return nil, errors.New("datatabletest: no more scripted results")
+	}
+	s := c.src.scripts[c.src.next]
+	c.src.next++
+
+	if s.QueryErr != nil {
+		return nil, s.QueryErr
+	}
+	return &scriptRows{script: s}, nil
+}
+
+type scriptStmt struct {
+	conn  *scriptConn
+	query string
+}
+
+func (s *scriptStmt) Close() error {
+	return nil
+}
+
+func (s *scriptStmt) NumInput() int {
+	return -1
+}
+
+func (s *scriptStmt) Exec(args []driver.Value) (driver.Result, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return s.conn.ExecContext(context.Background(), s.query, named)
+}
+
+func (s *scriptStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, nil)
+}
+
+type scriptRows struct {
+	script Script
+	pos    int
+}
+
+func (r *scriptRows) Columns() []string {
+	cols := make([]string, len(r.script.Columns))
+	for i, c := range r.script.Columns {
+		cols[i] = c.Name
+	}
+	return cols
+}
+
+func (r *scriptRows) Close() error {
+	return nil
+}
+
+func (r *scriptRows) Next(dest []driver.Value) error {
+	if r.script.Err != nil && r.pos >= r.script.ErrAfter {
+		return r.script.Err
+	}
+	if r.pos >= len(r.script.Rows) {
+		return io.EOF
+	}
+
+	row := r.script.Rows[r.pos]
+	r.pos++
+	for i := range dest {
+		dest[i] = nil
+		if i < len(row) {
+			dest[i] = row[i]
+		}
+	}
+	return nil
+}
+
+func (r *scriptRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.script.Columns[index].DBType
+}
+
+func (r *scriptRows) ColumnTypeScanType(index int) reflect.Type {
+	if t := r.script.Columns[index].ScanType; t != nil {
+		return t
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
+
+func (r *scriptRows) ColumnTypeLength(index int) (int64, bool) {
+	l := r.script.Columns[index].Length
+	return l, l > 0
+}
+
+func (r *scriptRows) ColumnTypeNullable(index int) (bool, bool) {
+	if n := r.script.Columns[index].Nullable; n != nil {
+		return *n, true
+	}
+	return false, false
+}
@@ -0,0 +1,82 @@
+package datatabletest
+
+import (
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOpen(t *testing.T) {
+	yes := true
+	db := Open(
+		Script{
+			Columns: []Column{{Name: "Code", DBType: "VARCHAR", ScanType: reflect.TypeOf(""), Length: 10, Nullable: &yes}},
+			Rows:    [][]driver.Value{{"A"}, {nil}},
+		},
+		Script{QueryErr: errors.New("bad query")},
+	)
+	defer db.Close()
+
+	rows, err := db.Query("SELECT Code FROM Items")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0].DatabaseTypeName() != "VARCHAR" || types[0].ScanType() != reflect.TypeOf("") {
+		t.Fatalf("unexpected column type %s %v", types[0].DatabaseTypeName(), types[0].ScanType())
+	}
+	if l, ok := types[0].Length(); !ok || l != 10 {
+		t.Fatalf("unexpected length %d", l)
+	}
+	if n, ok := types[0].Nullable(); !ok || !n {
+		t.Fatal("expected a nullable column")
+	}
+
+	var codes []interface{}
+	for rows.Next() {
+		var v interface{}
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		codes = append(codes, v)
+	}
+	rows.Close()
+	if len(codes) != 2 || codes[0] != "A" || codes[1] != nil {
+		t.Fatalf("unexpected rows %v", codes)
+	}
+
+	if _, err := db.Query("SELECT"); err == nil || err.Error() != "bad query" {
+		t.Fatalf("expected the scripted query error, got %v", err)
+	}
+	if _, err := db.Query("SELECT"); err == nil {
+		t.Fatal("expected an error after the last script")
+	}
+}
+
+func TestOpenClose(t *testing.T) {
+	db := Open(Script{})
+	sourcesMu.Lock()
+	n := len(sources)
+	_, ok := databases[db]
+	sourcesMu.Unlock()
+	if !ok || n == 0 {
+		t.Fatal("expected the database to be registered")
+	}
+
+	db.Close()
+	sourcesMu.Lock()
+	_, ok = databases[db]
+	m := len(sources)
+	sourcesMu.Unlock()
+	if ok || m != n-1 {
+		t.Fatalf("expected the scripts to be released, got %d sources from %d", m, n)
+	}
+	if Execs(db) != nil {
+		t.Fatal("expected no statements for a closed database")
+	}
+}
@@ -0,0 +1,306 @@
+package datatable
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CreateTableSQL - returns a CREATE TABLE statement for the table schema. Columns use their DBType
+// in the dialect when set, otherwise a type of the dialect is chosen from the Go type and Length.
+// Primary key and NotNull columns are created NOT NULL
+func CreateTableSQL(dt *DataTable, dialect Dialect) string {
+	return createTableSQL(dt, dialect, false)
+}
+
+// EnsureTable - creates the table in the database if it does not exist yet and returns true if it was created.
+// The catalog of the database is queried to find the table and errors of that query are returned
+func EnsureTable(ctx context.Context, db *sql.DB, dt *DataTable, dialect Dialect) (bool, error) {
+	if dt.Name == "" {
+		return false, errors.New("the table has no name")
+	}
+	if len(dt.Columns) == 0 {
+		return false, errors.New("the table has no columns")
+	}
+
+	var n int64
+	query, args := dialect.tableExistsSQL(dt.Name)
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return false, nil
+	}
+
+	if _, err := db.ExecContext(ctx, createTableSQL(dt, dialect, true)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// tableExistsSQL - returns a query counting the tables of the catalog with the name, and its arguments.
+// A schema qualified name such as dbo.Orders is looked up in that schema
+func (d Dialect) tableExistsSQL(name string) (string, []interface{}) {
+	schema, table := splitTableName(name)
+
+	switch d {
+	case DialectSQLServer:
+		return "SELECT COUNT(*) FROM sys.tables WHERE object_id = OBJECT_ID(@p1)", []interface{}{d.QuoteIdent(name)}
+	case DialectSQLite:
+		master := "sqlite_master"
+		if schema != "" {
+			master = d.QuoteIdent(schema) + "." + master
+		}
+		return "SELECT COUNT(*) FROM " + master + " WHERE type = 'table' AND name = ?", []interface{}{table}
+	}
+
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = " + d.Placeholder(1)
+	args := []interface{}{table}
+	switch {
+	case schema != "":
+		query += " AND table_schema = " + d.Placeholder(2)
+		args = append(args, schema)
+	case d == DialectPostgres:
+		query += " AND table_schema = current_schema()"
+	case d == DialectMySQL:
+		query += " AND table_schema = DATABASE()"
+	}
+	return query, args
+}
+
+func createTableSQL(dt *DataTable, dialect Dialect, ifNotExists bool) string {
+	var sb strings.Builder
+
+	switch {
+	case ifNotExists && dialect == DialectSQLServer:
+		sb.WriteString("IF OBJECT_ID(N'" + strings.ReplaceAll(dt.Name, "'", "''") + "', N'U') IS NULL\n")
+		sb.WriteString("CREATE TABLE ")
+	case ifNotExists:
+		sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	default:
+		sb.WriteString("CREATE TABLE ")
+	}
+	sb.WriteString(dialect.QuoteIdent(dt.Name))
+	sb.WriteString(" (\n")
+
+	var keys []string
+	for i, col := range dt.Columns {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		sb.WriteString("\t" + dialect.QuoteIdent(col.Name) + " " + dialect.ColumnType(col))
+		if col.NotNull || col.PrimaryKey {
+			sb.WriteString(" NOT NULL")
+		}
+		if col.PrimaryKey {
+			keys = append(keys, dialect.QuoteIdent(col.Name))
+		}
+	}
+
+	if len(keys) > 0 {
+		sb.WriteString(",\n\tPRIMARY KEY (" + strings.Join(keys, ", ") + ")")
+	}
+	sb.WriteString("\n)")
+	return sb.String()
+}
+
+// dialectTypes - the types of each dialect, in the order of the Dialect constants, for database types that
+// differ between dialects. An empty type means the dialect has no such type
+var dialectTypes = map[string][5]string{
+	"DATETIME":         {"TIMESTAMP", "DATETIME", "TIMESTAMP", "DATETIME2", ""},
+	"DATETIME2":        {"TIMESTAMP", "DATETIME", "TIMESTAMP", "DATETIME2", ""},
+	"SMALLDATETIME":    {"TIMESTAMP", "DATETIME", "TIMESTAMP", "SMALLDATETIME", ""},
+	"TIMESTAMP":        {"TIMESTAMP", "DATETIME", "TIMESTAMP", "DATETIME2", ""},
+	"DATETIMEOFFSET":   {"TIMESTAMP WITH TIME ZONE", "DATETIME", "TIMESTAMPTZ", "DATETIMEOFFSET", ""},
+	"TIMESTAMPTZ":      {"TIMESTAMP WITH TIME ZONE", "DATETIME", "TIMESTAMPTZ", "DATETIMEOFFSET", ""},
+	"NVARCHAR":         {"VARCHAR", "VARCHAR", "VARCHAR", "NVARCHAR", "TEXT"},
+	"NCHAR":            {"CHAR", "CHAR", "CHAR", "NCHAR", "TEXT"},
+	"NTEXT":            {"TEXT", "LONGTEXT", "TEXT", "NVARCHAR(MAX)", "TEXT"},
+	"TEXT":             {"TEXT", "TEXT", "TEXT", "NVARCHAR(MAX)", "TEXT"},
+	"LONGTEXT":         {"TEXT", "LONGTEXT", "TEXT", "NVARCHAR(MAX)", "TEXT"},
+	"MEDIUMTEXT":       {"TEXT", "MEDIUMTEXT", "TEXT", "NVARCHAR(MAX)", "TEXT"},
+	"BINARY":           {"BINARY", "BINARY", "BYTEA", "BINARY", "BLOB"},
+	"VARBINARY":        {"VARBINARY", "VARBINARY", "BYTEA", "VARBINARY", "BLOB"},
+	"IMAGE":            {"BLOB", "LONGBLOB", "BYTEA", "IMAGE", "BLOB"},
+	"BLOB":             {"BLOB", "BLOB", "BYTEA", "VARBINARY(MAX)", "BLOB"},
+	"MEDIUMBLOB":       {"BLOB", "MEDIUMBLOB", "BYTEA", "VARBINARY(MAX)", "BLOB"},
+	"LONGBLOB":         {"BLOB", "LONGBLOB", "BYTEA", "VARBINARY(MAX)", "BLOB"},
+	"BYTEA":            {"BLOB", "LONGBLOB", "BYTEA", "VARBINARY(MAX)", "BLOB"},
+	"UNIQUEIDENTIFIER": {"CHAR(36)", "CHAR(36)", "UUID", "UNIQUEIDENTIFIER", "TEXT"},
+	"UUID":             {"CHAR(36)", "CHAR(36)", "UUID", "UNIQUEIDENTIFIER", "TEXT"},
+	"MONEY":            {"DECIMAL(19,4)", "DECIMAL(19,4)", "NUMERIC(19,4)", "MONEY", "NUMERIC"},
+	"SMALLMONEY":       {"DECIMAL(10,4)", "DECIMAL(10,4)", "NUMERIC(10,4)", "SMALLMONEY", "NUMERIC"},
+	"BIT":              {"BOOLEAN", "BOOLEAN", "BOOLEAN", "BIT", "INTEGER"},
+	"BOOL":             {"BOOLEAN", "BOOLEAN", "BOOLEAN", "BIT", "INTEGER"},
+	"BOOLEAN":          {"BOOLEAN", "BOOLEAN", "BOOLEAN", "BIT", "INTEGER"},
+	"TINYINT":          {"SMALLINT", "TINYINT", "SMALLINT", "TINYINT", "INTEGER"},
+	"DOUBLE":           {"DOUBLE PRECISION", "DOUBLE", "DOUBLE PRECISION", "FLOAT", "REAL"},
+	"DOUBLE PRECISION": {"DOUBLE PRECISION", "DOUBLE", "DOUBLE PRECISION", "FLOAT", "REAL"},
+	"XML":              {"TEXT", "LONGTEXT", "XML", "XML", "TEXT"},
+}
+
+// ColumnType - returns the column definition type of the dialect for a column. A DBType is written in the
+// dialect when it is known. DECIMAL without a precision and types the dialect lacks use the type of the Go type
+func (d Dialect) ColumnType(col Column) string {
+	if t := d.dbColumnType(col); t != "" {
+		return t
+	}
+
+	if col.Type == nil {
+		return d.textType(0)
+	}
+
+	switch col.Type {
+	case reflect.TypeOf(time.Time{}):
+		switch d {
+		case DialectPostgres:
+			return "TIMESTAMP"
+		case DialectSQLServer:
+			return "DATETIME2"
+		}
+		return "DATETIME"
+	case reflect.TypeOf([]byte{}):
+		switch d {
+		case DialectPostgres:
+			return "BYTEA"
+		case DialectSQLServer:
+			if col.Length > 0 && col.Length <= 8000 {
+				return "VARBINARY(" + strconv.FormatInt(col.Length, 10) + ")"
+			}
+			return "VARBINARY(MAX)"
+		case DialectMySQL:
+			return "LONGBLOB"
+		}
+		return "BLOB"
+	}
+
+	switch col.Type.Kind() {
+	case reflect.Bool:
+		switch d {
+		case DialectSQLServer:
+			return "BIT"
+		case DialectSQLite:
+			return "INTEGER"
+		}
+		return "BOOLEAN"
+	case reflect.Int8, reflect.Uint8:
+		if d == DialectMySQL {
+			return "TINYINT"
+		}
+		return "SMALLINT"
+	case reflect.Int16:
+		return "SMALLINT"
+	case reflect.Uint16, reflect.Int32:
+		return "INTEGER"
+	case reflect.Int, reflect.Int64, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		if d == DialectSQLite {
+			return "INTEGER"
+		}
+		return "BIGINT"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		switch d {
+		case DialectPostgres, DialectGeneric:
+			return "DOUBLE PRECISION"
+		case DialectMySQL:
+			return "DOUBLE"
+		case DialectSQLite:
+			return "REAL"
+		}
+		return "FLOAT"
+	}
+	return d.textType(col.Length)
+}
+
+// dbColumnType - returns the DBType of a column in the dialect, with the size of the DBType or the Length of
+// character and binary columns. Returns an empty string if there is no DBType or the dialect has no such type
+func (d Dialect) dbColumnType(col Column) string {
+	t := strings.ToUpper(strings.TrimSpace(col.DBType))
+	if t == "" {
+		return ""
+	}
+	name, size := t, ""
+	if i := strings.IndexByte(t, '('); i != -1 {
+		name, size = strings.TrimSpace(t[:i]), strings.ReplaceAll(t[i:], " ", "")
+	}
+	if size == "(MAX)" && d != DialectSQLServer {
+		return ""
+	}
+
+	types, ok := dialectTypes[name]
+	if !ok {
+		switch {
+		case (name == "DECIMAL" || name == "NUMERIC") && size == "":
+			return ""
+		case size != "":
+			return t
+		}
+		return name + lengthSuffix(name, col.Length)
+	}
+
+	mapped := types[d]
+	switch {
+	case mapped == "":
+		return ""
+	case strings.Contains(mapped, "("):
+		return mapped
+	case size != "":
+		// The size only carries over to types that take one, such as VARCHAR(40) or DATETIME2(3)
+		switch mapped {
+		case "CHAR", "NCHAR", "VARCHAR", "NVARCHAR", "BINARY", "VARBINARY", "DATETIME", "DATETIME2", "TIMESTAMP", "TIMESTAMPTZ", "DATETIMEOFFSET":
+			return mapped + size
+		}
+		return mapped
+	}
+
+	// MySQL requires a length for VARCHAR and VARBINARY
+	suffix := lengthSuffix(mapped, col.Length)
+	if suffix == "" && d == DialectMySQL && (mapped == "VARCHAR" || mapped == "VARBINARY") {
+		return ""
+	}
+	return mapped + suffix
+}
+
+// lengthSuffix - returns the length of a character or binary type in parentheses, or an empty string for other
+// types and lengths that are not set
+func lengthSuffix(name string, length int64) string {
+	if length <= 0 {
+		return ""
+	}
+	switch name {
+	case "CHAR", "NCHAR", "VARCHAR", "NVARCHAR", "BINARY", "VARBINARY", "CHARACTER VARYING":
+		return "(" + strconv.FormatInt(length, 10) + ")"
+	}
+	return ""
+}
+
+// textType - returns a character type with the length or unlimited text if length is zero
+func (d Dialect) textType(length int64) string {
+	if d == DialectSQLite {
+		return "TEXT"
+	}
+
+	if length > 0 {
+		switch {
+		case d == DialectSQLServer && length <= 4000:
+			return "NVARCHAR(" + strconv.FormatInt(length, 10) + ")"
+		case d == DialectMySQL && length > 16383:
+			return "LONGTEXT"
+		case d != DialectSQLServer:
+			return "VARCHAR(" + strconv.FormatInt(length, 10) + ")"
+		}
+	}
+
+	if d == DialectSQLServer {
+		return "NVARCHAR(MAX)"
+	}
+	if d == DialectMySQL {
+		return "LONGTEXT"
+	}
+	return "TEXT"
+}
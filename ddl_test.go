@@ -0,0 +1,176 @@
+package datatable
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eaglebush/datatable/datatabletest"
+)
+
+func TestCreateTableSQL(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	dt.AddColumn("Customer", reflect.TypeOf(""), 40, "")
+	dt.AddColumn("Amount", reflect.TypeOf(0.0), 0, "DECIMAL(18,2)")
+	dt.AddColumn("Created", reflect.TypeOf(time.Time{}), 0, "")
+	dt.AddColumn("Paid", reflect.TypeOf(false), 0, "")
+	dt.SetPrimaryKey("ID")
+	dt.Columns[1].NotNull = true
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectSQLServer, "CREATE TABLE [Orders] (\n" +
+			"\t[id] BIGINT NOT NULL,\n" +
+			"\t[customer] NVARCHAR(40) NOT NULL,\n" +
+			"\t[amount] DECIMAL(18,2),\n" +
+			"\t[created] DATETIME2,\n" +
+			"\t[paid] BIT,\n" +
+			"\tPRIMARY KEY ([id])\n)"},
+		{DialectPostgres, "CREATE TABLE \"Orders\" (\n" +
+			"\t\"id\" BIGINT NOT NULL,\n" +
+			"\t\"customer\" VARCHAR(40) NOT NULL,\n" +
+			"\t\"amount\" DECIMAL(18,2),\n" +
+			"\t\"created\" TIMESTAMP,\n" +
+			"\t\"paid\" BOOLEAN,\n" +
+			"\tPRIMARY KEY (\"id\")\n)"},
+		{DialectSQLite, "CREATE TABLE \"Orders\" (\n" +
+			"\t\"id\" INTEGER NOT NULL,\n" +
+			"\t\"customer\" TEXT NOT NULL,\n" +
+			"\t\"amount\" DECIMAL(18,2),\n" +
+			"\t\"created\" DATETIME,\n" +
+			"\t\"paid\" INTEGER,\n" +
+			"\tPRIMARY KEY (\"id\")\n)"},
+	}
+	for _, tc := range tests {
+		if got := CreateTableSQL(dt, tc.dialect); got != tc.want {
+			t.Errorf("dialect %d: got\n%s\nexpected\n%s", tc.dialect, got, tc.want)
+		}
+	}
+}
+
+func TestDialectColumnType(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		col     Column
+		want    string
+	}{
+		{DialectMySQL, Column{Type: reflect.TypeOf("")}, "LONGTEXT"},
+		{DialectSQLServer, Column{Type: reflect.TypeOf(""), Length: 5000}, "NVARCHAR(MAX)"},
+		{DialectMySQL, Column{Type: reflect.TypeOf([]byte{})}, "LONGBLOB"},
+		{DialectGeneric, Column{Type: reflect.TypeOf(float32(0))}, "REAL"},
+		{DialectPostgres, Column{Type: reflect.TypeOf(int16(0))}, "SMALLINT"},
+		{DialectPostgres, Column{DBType: "varchar", Length: 10}, "VARCHAR(10)"},
+		{DialectGeneric, Column{}, "TEXT"},
+	}
+	for _, tc := range tests {
+		if got := tc.dialect.ColumnType(tc.col); got != tc.want {
+			t.Errorf("dialect %d, column %+v: got %s, expected %s", tc.dialect, tc.col, got, tc.want)
+		}
+	}
+}
+
+func TestDialectColumnTypeDBType(t *testing.T) {
+	str, bin := reflect.TypeOf(""), reflect.TypeOf([]byte{})
+	tests := []struct {
+		col  Column
+		want [5]string // Generic, MySQL, Postgres, SQLServer, SQLite
+	}{
+		{Column{Type: reflect.TypeOf(time.Time{}), DBType: "datetime"}, [5]string{"TIMESTAMP", "DATETIME", "TIMESTAMP", "DATETIME2", "DATETIME"}},
+		{Column{Type: reflect.TypeOf(time.Time{}), DBType: "DATETIME2(3)"}, [5]string{"TIMESTAMP(3)", "DATETIME(3)", "TIMESTAMP(3)", "DATETIME2(3)", "DATETIME"}},
+		{Column{Type: str, DBType: "NVARCHAR", Length: 40}, [5]string{"VARCHAR(40)", "VARCHAR(40)", "VARCHAR(40)", "NVARCHAR(40)", "TEXT"}},
+		{Column{Type: str, DBType: "NVARCHAR"}, [5]string{"VARCHAR", "LONGTEXT", "VARCHAR", "NVARCHAR", "TEXT"}},
+		{Column{Type: str, DBType: "nvarchar(max)"}, [5]string{"TEXT", "LONGTEXT", "TEXT", "NVARCHAR(MAX)", "TEXT"}},
+		{Column{Type: bin, DBType: "IMAGE"}, [5]string{"BLOB", "LONGBLOB", "BYTEA", "IMAGE", "BLOB"}},
+		{Column{Type: bin, DBType: "VARBINARY(16)"}, [5]string{"VARBINARY(16)", "VARBINARY(16)", "BYTEA", "VARBINARY(16)", "BLOB"}},
+		{Column{Type: str, DBType: "UNIQUEIDENTIFIER"}, [5]string{"CHAR(36)", "CHAR(36)", "UUID", "UNIQUEIDENTIFIER", "TEXT"}},
+		{Column{Type: reflect.TypeOf(0.0), DBType: "MONEY"}, [5]string{"DECIMAL(19,4)", "DECIMAL(19,4)", "NUMERIC(19,4)", "MONEY", "NUMERIC"}},
+		{Column{Type: reflect.TypeOf(0.0), DBType: "DECIMAL"}, [5]string{"DOUBLE PRECISION", "DOUBLE", "DOUBLE PRECISION", "FLOAT", "REAL"}},
+		{Column{Type: reflect.TypeOf(0.0), DBType: "NUMERIC(10, 2)"}, [5]string{"NUMERIC(10, 2)", "NUMERIC(10, 2)", "NUMERIC(10, 2)", "NUMERIC(10, 2)", "NUMERIC(10, 2)"}},
+		{Column{Type: reflect.TypeOf(false), DBType: "BIT"}, [5]string{"BOOLEAN", "BOOLEAN", "BOOLEAN", "BIT", "INTEGER"}},
+		{Column{Type: str, DBType: "JSONB"}, [5]string{"JSONB", "JSONB", "JSONB", "JSONB", "JSONB"}},
+	}
+	for _, tc := range tests {
+		for d, want := range tc.want {
+			if got := Dialect(d).ColumnType(tc.col); got != want {
+				t.Errorf("dialect %d, DBType %s: got %s, expected %s", d, tc.col.DBType, got, want)
+			}
+		}
+	}
+}
+
+func TestEnsureTable(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	count := func(n int64) datatabletest.Script {
+		return datatabletest.Script{Columns: []datatabletest.Column{{Name: "n"}}, Rows: [][]driver.Value{{n}}}
+	}
+
+	// The catalog holds the table
+	db := datatabletest.Open(count(1))
+	defer db.Close()
+
+	created, err := EnsureTable(context.Background(), db, dt, DialectSQLServer)
+	if err != nil || created {
+		t.Fatalf("expected an existing table, got %v, %v", created, err)
+	}
+	if execs := datatabletest.Execs(db); len(execs) != 0 {
+		t.Fatalf("unexpected statements %v", execs)
+	}
+
+	// The catalog does not hold the table, so it is created
+	db2 := datatabletest.Open(count(0))
+	defer db2.Close()
+
+	created, err = EnsureTable(context.Background(), db2, dt, DialectSQLServer)
+	if err != nil || !created {
+		t.Fatalf("expected the table to be created, got %v, %v", created, err)
+	}
+	execs := datatabletest.Execs(db2)
+	if len(execs) != 1 || !strings.HasPrefix(execs[0].Query, "IF OBJECT_ID(N'Orders', N'U') IS NULL\nCREATE TABLE [Orders]") {
+		t.Fatalf("unexpected statements %v", execs)
+	}
+
+	// Errors of the catalog query, such as a lost connection, are returned without creating the table
+	lost := errors.New("connection reset")
+	db3 := datatabletest.Open(datatabletest.Script{QueryErr: lost})
+	defer db3.Close()
+
+	if created, err := EnsureTable(context.Background(), db3, dt, DialectSQLServer); !errors.Is(err, lost) || created {
+		t.Fatalf("expected the catalog error, got %v, %v", created, err)
+	}
+	if execs := datatabletest.Execs(db3); len(execs) != 0 {
+		t.Fatalf("unexpected statements %v", execs)
+	}
+
+	if _, err := EnsureTable(context.Background(), db2, NewDataTable(""), DialectSQLServer); err == nil {
+		t.Fatal("expected an error for a table without a name")
+	}
+}
+
+func TestTableExistsSQL(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		name    string
+		want    string
+		args    []interface{}
+	}{
+		{DialectSQLServer, "dbo.Orders", "SELECT COUNT(*) FROM sys.tables WHERE object_id = OBJECT_ID(@p1)", []interface{}{"[dbo].[Orders]"}},
+		{DialectPostgres, "Orders", "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = $1 AND table_schema = current_schema()", []interface{}{"Orders"}},
+		{DialectPostgres, "sales.Orders", "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = $1 AND table_schema = $2", []interface{}{"Orders", "sales"}},
+		{DialectMySQL, "Orders", "SELECT COUNT(*) FROM information_schema.tables WHERE table_name = ? AND table_schema = DATABASE()", []interface{}{"Orders"}},
+		{DialectSQLite, "Orders", "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", []interface{}{"Orders"}},
+	}
+	for _, tc := range tests {
+		got, args := tc.dialect.tableExistsSQL(tc.name)
+		if got != tc.want || !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("dialect %d, %s: got %s %v", tc.dialect, tc.name, got, args)
+		}
+	}
+}
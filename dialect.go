@@ -0,0 +1,57 @@
+package datatable
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect - the SQL flavor of a database server
+type Dialect int
+
+// Dialects
+const (
+	DialectGeneric Dialect = iota
+	DialectMySQL
+	DialectPostgres
+	DialectSQLServer
+	DialectSQLite
+)
+
+// Placeholder - returns the parameter placeholder for the nth parameter, starting at 1
+func (d Dialect) Placeholder(n int) string {
+	switch d {
+	case DialectPostgres:
+		return "$" + strconv.Itoa(n)
+	case DialectSQLServer:
+		return "@p" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// QuoteIdent - quotes an identifier. Names with dots are quoted part by part so that
+// schema qualified names such as dbo.Staging keep working
+func (d Dialect) QuoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		switch d {
+		case DialectMySQL:
+			parts[i] = "`" + strings.ReplaceAll(p, "`", "``") + "`"
+		case DialectSQLServer:
+			parts[i] = "[" + strings.ReplaceAll(p, "]", "]]") + "]"
+		default:
+			parts[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// MaxParams - returns the maximum number of parameters in one statement
+func (d Dialect) MaxParams() int {
+	switch d {
+	case DialectSQLServer:
+		return 2099
+	case DialectPostgres, DialectMySQL:
+		return 65535
+	}
+	return 999
+}
@@ -0,0 +1,198 @@
+package datatable
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CellChange - a cell whose value differs between two tables
+type CellChange struct {
+	Column string      `json:"column"`
+	Old    interface{} `json:"old"`
+	New    interface{} `json:"new"`
+}
+
+// RowChange - a row found in both tables with one or more changed cells
+type RowChange struct {
+	Key   []interface{} `json:"key"`
+	Cells []CellChange  `json:"cells"`
+}
+
+// DiffResult - the differences between two tables as returned by Diff
+type DiffResult struct {
+	KeyColumns     []string        `json:"keyColumns"`
+	AddedColumns   []string        `json:"addedColumns,omitempty"`
+	RemovedColumns []string        `json:"removedColumns,omitempty"`
+	Added          [][]interface{} `json:"added"`
+	Removed        [][]interface{} `json:"removed"`
+	Changed        []RowChange     `json:"changed"`
+}
+
+// Diff - compares table a with table b. Rows are matched by the key columns, or by the primary key of a
+// if none are given, or by position if a has no primary key. Keys only in b are reported as added and
+// keys only in a as removed. Cells of the columns found in both tables are compared by value so that
+// []uint8 and string, or int and int64, holding the same value are equal. Returns an error if a key column
+// does not exist in either table
+func Diff(a, b *DataTable, keyCols ...string) (DiffResult, error) {
+	if len(keyCols) == 0 {
+		keyCols = a.PrimaryKey()
+	}
+
+	res := DiffResult{
+		KeyColumns: keyCols,
+		Added:      [][]interface{}{},
+		Removed:    [][]interface{}{},
+		Changed:    []RowChange{},
+	}
+
+	akey := make([]int, len(keyCols))
+	bkey := make([]int, len(keyCols))
+	iskey := make(map[int]bool, len(keyCols))
+	for i, k := range keyCols {
+		if akey[i] = a.columnIndex(k); akey[i] == -1 {
+			return DiffResult{}, fmt.Errorf("key column %s does not exist in %s", k, a.Name)
+		}
+		if bkey[i] = b.columnIndex(k); bkey[i] == -1 {
+			return DiffResult{}, fmt.Errorf("key column %s does not exist in %s", k, b.Name)
+		}
+		iskey[akey[i]] = true
+	}
+
+	// Columns compared by value, as pairs of indexes into a and b
+	var cols [][2]int
+	for i, col := range a.Columns {
+		j := b.columnIndex(col.Name)
+		if j == -1 {
+			res.RemovedColumns = append(res.RemovedColumns, col.Name)
+			continue
+		}
+		if !iskey[i] {
+			cols = append(cols, [2]int{i, j})
+		}
+	}
+	for _, col := range b.Columns {
+		if a.columnIndex(col.Name) == -1 {
+			res.AddedColumns = append(res.AddedColumns, col.Name)
+		}
+	}
+
+	keyOf := func(row *Row, index int, kc []int) (string, []interface{}) {
+		if len(kc) == 0 {
+			return keyString(index), []interface{}{index}
+		}
+		vals := make([]interface{}, len(kc))
+		for i, c := range kc {
+			if c < len(row.Cells) {
+				vals[i] = row.Value(c)
+			}
+		}
+		return keyString(vals...), vals
+	}
+
+	brows := make(map[string]int, len(b.Rows))
+	for i := range b.Rows {
+		k, _ := keyOf(&b.Rows[i], i, bkey)
+		brows[k] = i
+	}
+
+	found := make(map[string]bool, len(a.Rows))
+	for i := range a.Rows {
+		ar := &a.Rows[i]
+		k, kv := keyOf(ar, i, akey)
+		found[k] = true
+
+		j, ok := brows[k]
+		if !ok {
+			res.Removed = append(res.Removed, kv)
+			continue
+		}
+
+		br := &b.Rows[j]
+		var changes []CellChange
+		for _, c := range cols {
+			ov, nv := cellValue(ar, c[0]), cellValue(br, c[1])
+			if !valuesEqual(ov, nv) {
+				changes = append(changes, CellChange{Column: a.Columns[c[0]].Name, Old: ov, New: nv})
+			}
+		}
+		if len(changes) > 0 {
+			res.Changed = append(res.Changed, RowChange{Key: kv, Cells: changes})
+		}
+	}
+
+	for i := range b.Rows {
+		k, kv := keyOf(&b.Rows[i], i, bkey)
+		if !found[k] {
+			res.Added = append(res.Added, kv)
+		}
+	}
+
+	return res, nil
+}
+
+// cellValue - gets the value of a cell by index or nil if the row has no such cell
+func cellValue(row *Row, index int) interface{} {
+	if index < 0 || index >= len(row.Cells) {
+		return nil
+	}
+	return row.Value(index)
+}
+
+// HasChanges - returns true if the tables differ in columns or rows
+func (d DiffResult) HasChanges() bool {
+	return len(d.AddedColumns) > 0 || len(d.RemovedColumns) > 0 ||
+		len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// String - returns a human readable report of the differences, one line per added or removed
+// column or row and one line per changed cell
+func (d DiffResult) String() string {
+	var sb strings.Builder
+
+	for _, c := range d.AddedColumns {
+		fmt.Fprintf(&sb, "+ column %s\n", c)
+	}
+	for _, c := range d.RemovedColumns {
+		fmt.Fprintf(&sb, "- column %s\n", c)
+	}
+	for _, k := range d.Added {
+		fmt.Fprintf(&sb, "+ row %s\n", formatKey(k))
+	}
+	for _, k := range d.Removed {
+		fmt.Fprintf(&sb, "- row %s\n", formatKey(k))
+	}
+	for _, rc := range d.Changed {
+		for _, c := range rc.Cells {
+			fmt.Fprintf(&sb, "~ row %s %s: %s -> %s\n", formatKey(rc.Key), c.Column, formatDiffValue(c.Old), formatDiffValue(c.New))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "no differences\n"
+	}
+	return sb.String()
+}
+
+// JSON - returns the differences as indented JSON
+func (d DiffResult) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+func formatKey(key []interface{}) string {
+	parts := make([]string, len(key))
+	for i, k := range key {
+		parts[i] = formatDiffValue(k)
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func formatDiffValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,88 @@
+package datatable
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	prod := NewDataTable("Production")
+	prod.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0)), PrimaryKey: true},
+		{Name: "Name", Type: reflect.TypeOf(""), Length: 20},
+	})
+	for i, name := range []string{"One", "Two", "Three"} {
+		r := prod.NewRow()
+		r.Cells[0].Value = int64(i + 1)
+		r.Cells[1].Value = name
+		prod.AddRow(&r)
+	}
+
+	replica := NewDataTable("Replica")
+	replica.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Name", Type: reflect.TypeOf([]byte{}), DBType: "VARCHAR"},
+		{Name: "Note", Type: reflect.TypeOf("")},
+	})
+	for _, v := range []struct {
+		id   int
+		name string
+	}{{1, "One"}, {2, "Deux"}, {4, "Four"}} {
+		r := replica.NewRow()
+		r.Cells[0].Value = v.id
+		r.Cells[1].Value = []byte(v.name)
+		replica.AddRow(&r)
+	}
+
+	d, err := Diff(prod, replica)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.HasChanges() {
+		t.Fatal("expected differences")
+	}
+	if len(d.Added) != 1 || len(d.Removed) != 1 || len(d.Changed) != 1 {
+		t.Fatalf("unexpected diff %+v", d)
+	}
+	if d.Added[0][0] != 4 || d.Removed[0][0] != int64(3) {
+		t.Fatalf("unexpected added or removed keys %v %v", d.Added, d.Removed)
+	}
+
+	c := d.Changed[0].Cells[0]
+	if c.Column != "Name" || c.Old != "Two" || c.New != "Deux" {
+		t.Fatalf("unexpected change %+v", c)
+	}
+	if len(d.AddedColumns) != 1 || d.AddedColumns[0] != "Note" {
+		t.Fatalf("unexpected added columns %v", d.AddedColumns)
+	}
+
+	report := d.String()
+	for _, line := range []string{"+ column Note", "+ row (4)", "- row (3)", `~ row (2) Name: "Two" -> "Deux"`} {
+		if !strings.Contains(report, line) {
+			t.Errorf("expected %q in report:\n%s", line, report)
+		}
+	}
+
+	b, err := d.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back DiffResult
+	if err := json.Unmarshal(b, &back); err != nil || len(back.Changed) != 1 {
+		t.Fatalf("unexpected JSON report %s", b)
+	}
+
+	if d, err := Diff(prod, prod.Copy()); err != nil || d.HasChanges() {
+		t.Fatalf("expected no differences with a copy, got %v", err)
+	}
+
+	if _, err := Diff(prod, replica, "Nope"); err == nil || !strings.Contains(err.Error(), "Nope") {
+		t.Errorf("expected an error for a missing key column, got %v", err)
+	}
+	replica.Columns[0].Name = "Key"
+	if _, err := Diff(prod, replica, "ID"); err == nil || !strings.Contains(err.Error(), "Replica") {
+		t.Errorf("expected an error for a key column missing from b, got %v", err)
+	}
+}
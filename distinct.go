@@ -0,0 +1,191 @@
+package datatable
+
+import (
+	"reflect"
+	"strings"
+)
+
+// DuplicateKeep - which row of a set of duplicates DropDuplicates keeps
+type DuplicateKeep int
+
+const (
+	KeepFirst DuplicateKeep = iota // keep the first row of each set of duplicates
+	KeepLast                       // keep the last row of each set of duplicates
+)
+
+// Distinct - returns a new table with the named columns, or all columns if none are named, and one row for
+// each distinct combination of their values in the order they first appear. Values are compared by value, so
+// int and int64, or []uint8 and string in text columns, holding the same value are equal. Returns nil if a
+// column does not exist
+func (dt *DataTable) Distinct(cols ...string) *DataTable {
+	idx, ok := dt.distinctColumns(cols)
+	if !ok {
+		return nil
+	}
+
+	res := NewDataTable(dt.Name)
+	for _, i := range idx {
+		col := dt.Columns[i]
+		col.PrimaryKey = false
+		res.AddColumns([]Column{col})
+	}
+
+	key := dt.distinctKey(idx)
+	seen := make(map[string]bool)
+	var rows []Row
+	for i := range dt.Rows {
+		k := key(&dt.Rows[i])
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		r := res.NewRow()
+		for j, c := range idx {
+			if c < len(dt.Rows[i].Cells) {
+				r.Cells[j].Value = copyValue(dt.Rows[i].Cells[c].Value)
+			}
+		}
+		rows = append(rows, r)
+	}
+	res.AddRows(rows)
+	return res
+}
+
+// DropDuplicates - removes rows whose values in the named columns, or in all columns if none are named,
+// match those of another row, keeping the first or last of each set. Returns the number of rows removed,
+// or -1 without removing any if a column does not exist
+func (dt *DataTable) DropDuplicates(keep DuplicateKeep, cols ...string) int {
+	idx, ok := dt.distinctColumns(cols)
+	if !ok {
+		return -1
+	}
+
+	key := dt.distinctKey(idx)
+	drop := make([]bool, len(dt.Rows))
+	seen := make(map[string]bool)
+	for n := range dt.Rows {
+		i := n
+		if keep == KeepLast {
+			i = len(dt.Rows) - 1 - n
+		}
+		k := key(&dt.Rows[i])
+		drop[i] = seen[k]
+		seen[k] = true
+	}
+
+	// RemoveWhere visits rows in order, so the position of each row is its index before removal
+	i := 0
+	return dt.RemoveWhere(func(row *Row) bool {
+		i++
+		return drop[i-1]
+	})
+}
+
+// DistinctValues - returns the distinct non-null values of a column in the order they first appear, or nil if
+// the column does not exist. Text held as []uint8 is returned as string
+func (dt *DataTable) DistinctValues(col string) []interface{} {
+	idx := dt.columnIndex(col)
+	if idx == -1 {
+		return nil
+	}
+
+	key := dt.distinctKey([]int{idx})
+	binary := isBinaryColumn(dt.Columns[idx])
+	seen := make(map[string]bool)
+	values := make([]interface{}, 0)
+	for i := range dt.Rows {
+		r := &dt.Rows[i]
+		if idx >= len(r.Cells) || r.Cells[idx].Value == nil {
+			continue
+		}
+		k := key(r)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		v := r.Cells[idx].Value
+		if b, ok := v.([]byte); ok && !binary {
+			v = string(b)
+		}
+		values = append(values, copyValue(v))
+	}
+	return values
+}
+
+// distinctColumns - returns the indexes of the named columns, or of all columns if none are named
+func (dt *DataTable) distinctColumns(cols []string) ([]int, bool) {
+	if len(cols) == 0 {
+		idx := make([]int, len(dt.Columns))
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx, true
+	}
+
+	idx := make([]int, len(cols))
+	for i, name := range cols {
+		if idx[i] = dt.columnIndex(name); idx[i] == -1 {
+			return nil, false
+		}
+	}
+	return idx, true
+}
+
+// distinctKey - returns a function building a map key from the cells of a row at the column indexes.
+// Values of binary columns are keyed by their bytes and type so that []uint8 does not match string
+func (dt *DataTable) distinctKey(idx []int) func(row *Row) string {
+	binary := make([]bool, len(idx))
+	for i, c := range idx {
+		binary[i] = isBinaryColumn(dt.Columns[c])
+	}
+
+	return func(row *Row) string {
+		var b []byte
+		for i, c := range idx {
+			var v interface{}
+			if c < len(row.Cells) {
+				v = row.Cells[c].Value
+			}
+			if bv, ok := v.([]byte); ok && binary[i] {
+				b = appendKeyPart(b, "b:"+string(bv))
+				continue
+			}
+			b = appendKeyPart(b, keyPart(v))
+		}
+		return string(b)
+	}
+}
+
+// isBinaryColumn - tells if a column holds binary data rather than text. A binary DBType such as VARBINARY,
+// BLOB or IMAGE makes a column binary and any other DBType makes it text, as drivers return text as []uint8.
+// Without a DBType a column is binary if its type is []byte
+func isBinaryColumn(col Column) bool {
+	t := strings.ToUpper(strings.TrimSpace(col.DBType))
+	if i := strings.IndexByte(t, '('); i != -1 {
+		t = strings.TrimSpace(t[:i])
+	}
+	switch t {
+	case "BINARY", "VARBINARY", "IMAGE", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA":
+		return true
+	case "":
+		return col.Type == reflect.TypeOf([]byte{})
+	}
+	return false
+}
+
+// isNumericDBType - tells if a database type is numeric, in which case the []uint8 values drivers return for it
+// hold numbers as text
+func isNumericDBType(dbType string) bool {
+	t := strings.ToUpper(strings.TrimSpace(dbType))
+	if i := strings.IndexByte(t, '('); i != -1 {
+		t = strings.TrimSpace(t[:i])
+	}
+	switch t {
+	case "TINYINT", "SMALLINT", "INT", "INTEGER", "BIGINT", "MEDIUMINT", "INT2", "INT4", "INT8",
+		"DECIMAL", "NUMERIC", "FLOAT", "REAL", "DOUBLE", "DOUBLE PRECISION", "MONEY", "SMALLMONEY", "FLOAT4", "FLOAT8":
+		return true
+	}
+	return false
+}
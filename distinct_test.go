@@ -0,0 +1,155 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDistinct(t *testing.T) {
+	dt := NewDataTable("Imports")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0), PrimaryKey: true},
+		{Name: "Email", Type: reflect.TypeOf(""), DBType: "VARCHAR"},
+		{Name: "Country", Type: reflect.TypeOf("")},
+		{Name: "Hash", Type: reflect.TypeOf([]byte{}), DBType: "VARBINARY"},
+	})
+	for _, vals := range [][]interface{}{
+		{1, "a@x.com", "PH", []byte("h1")},
+		{2, []byte("a@x.com"), "PH", "h1"},
+		{3, "b@x.com", nil, []byte("h2")},
+		{4, "a@x.com", "US", []byte("h1")},
+		{5, "b@x.com", nil, []byte("h2")},
+	} {
+		r := dt.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		dt.AddRow(&r)
+	}
+
+	d := dt.Distinct("email", "country")
+	if d == nil || len(d.Columns) != 2 || d.Columns[0].Name != "Email" {
+		t.Fatalf("unexpected columns %v", d)
+	}
+	want := [][]interface{}{{"a@x.com", "PH"}, {"b@x.com", nil}, {"a@x.com", "US"}}
+	if len(d.Rows) != len(want) {
+		t.Fatalf("got %d rows, expected %d", len(d.Rows), len(want))
+	}
+	for i, w := range want {
+		for j, v := range w {
+			if got := d.Rows[i].Value(j); got != v {
+				t.Errorf("row %d column %d: got %v, expected %v", i, j, got, v)
+			}
+		}
+		if d.Rows[i].Cells[0].RowIndex != i {
+			t.Errorf("row %d has RowIndex %d", i, d.Rows[i].Cells[0].RowIndex)
+		}
+	}
+
+	// []uint8 and string differ in the binary column
+	if n := len(dt.Distinct("Email", "Hash").Rows); n != 3 {
+		t.Errorf("got %d distinct email and hash rows, expected 3", n)
+	}
+	if n := len(dt.Distinct().Rows); n != 5 {
+		t.Errorf("got %d distinct rows, expected 5", n)
+	}
+	if dt.Distinct("Missing") != nil {
+		t.Error("expected nil for a missing column")
+	}
+}
+
+func TestDropDuplicates(t *testing.T) {
+	src := NewDataTable("Imports")
+	src.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0), PrimaryKey: true},
+		{Name: "Email", Type: reflect.TypeOf(""), DBType: "VARCHAR"},
+		{Name: "Country", Type: reflect.TypeOf("")},
+	})
+	for _, vals := range [][]interface{}{
+		{1, "a@x.com", "PH"}, {2, []byte("a@x.com"), "PH"}, {3, "b@x.com", nil}, {4, "a@x.com", "US"}, {5, "b@x.com", nil},
+	} {
+		r := src.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		src.AddRow(&r)
+	}
+
+	dt := src.Copy()
+	if n := dt.DropDuplicates(KeepFirst, "Email"); n != 3 {
+		t.Fatalf("removed %d rows, expected 3", n)
+	}
+	checkRowIDs(t, dt, 1, 3)
+
+	dt = src.Copy()
+	if n := dt.DropDuplicates(KeepLast, "Email", "Country"); n != 2 {
+		t.Fatalf("removed %d rows, expected 2", n)
+	}
+	checkRowIDs(t, dt, 2, 4, 5)
+
+	if n := dt.DropDuplicates(KeepFirst); n != 0 {
+		t.Errorf("removed %d rows of distinct rows", n)
+	}
+	if n := dt.DropDuplicates(KeepFirst, "Missing"); n != -1 || len(dt.Rows) != 3 {
+		t.Errorf("got %d for a missing column", n)
+	}
+}
+
+func TestDistinctValues(t *testing.T) {
+	dt := NewDataTable("Imports")
+	dt.AddColumns([]Column{
+		{Name: "Email", Type: reflect.TypeOf(""), DBType: "VARCHAR"},
+		{Name: "Country", Type: reflect.TypeOf("")},
+		{Name: "Hash", Type: reflect.TypeOf([]byte{}), DBType: "VARBINARY"},
+	})
+	for _, vals := range [][]interface{}{
+		{"a@x.com", "PH", []byte("h1")},
+		{[]byte("a@x.com"), "PH", "h1"},
+		{"b@x.com", nil, []byte("h2")},
+		{"a@x.com", "US", []byte("h1")},
+	} {
+		r := dt.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		dt.AddRow(&r)
+	}
+
+	if got, want := dt.DistinctValues("email"), []interface{}{"a@x.com", "b@x.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+	if got, want := dt.DistinctValues("Country"), []interface{}{"PH", "US"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+	if got, want := dt.DistinctValues("Hash"), []interface{}{[]byte("h1"), "h1", []byte("h2")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, expected %#v", got, want)
+	}
+	if dt.DistinctValues("Missing") != nil {
+		t.Error("expected nil for a missing column")
+	}
+	if got := NewDataTable("Empty").DistinctValues("x"); got != nil {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestDistinctSeparator(t *testing.T) {
+	dt := NewDataTable("Blobs")
+	dt.AddColumns([]Column{
+		{Name: "B", Type: reflect.TypeOf([]byte{})},
+		{Name: "S", Type: reflect.TypeOf("")},
+	})
+	for _, v := range [][2]string{{"x\x00string:y", "z"}, {"x", "y\x00string:z"}} {
+		r := dt.NewRow()
+		r.Cells[0].Value = []byte(v[0])
+		r.Cells[1].Value = v[1]
+		dt.AddRow(&r)
+	}
+
+	// Values holding the separator of an unprefixed key would build the same key
+	if n := len(dt.Distinct().Rows); n != 2 {
+		t.Errorf("got %d distinct rows, expected 2", n)
+	}
+	if keyString("x\x00string:y", "z") == keyString("x", "y\x00string:z") {
+		t.Error("different values built the same key")
+	}
+}
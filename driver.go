@@ -0,0 +1,274 @@
+package datatable
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DriverName - the name of the database/sql driver that queries registered data tables
+const DriverName = "datatable"
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]map[string]*DataTable)
+)
+
+func init() {
+	sql.Register(DriverName, &tableDriver{})
+}
+
+// RegisterTables - makes tables queryable through sql.Open(DriverName, name). Queries refer to the
+// tables by their Name and are run with QueryTables, with ? or $n placeholders for arguments.
+// Registering a table with the same name again replaces it
+func RegisterTables(name string, tables ...*DataTable) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+
+	src, ok := sources[name]
+	if !ok {
+		src = make(map[string]*DataTable)
+		sources[name] = src
+	}
+	for _, dt := range tables {
+		src[strings.ToLower(dt.Name)] = dt
+	}
+}
+
+// UnregisterTables - removes a data source registered with RegisterTables
+func UnregisterTables(name string) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	delete(sources, name)
+}
+
+// sourceTables - returns a copy of the tables of a data source
+func sourceTables(name string) (map[string]*DataTable, error) {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+
+	src, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("data source %s is not registered", name)
+	}
+	tables := make(map[string]*DataTable, len(src))
+	for k, v := range src {
+		tables[k] = v
+	}
+	return tables, nil
+}
+
+type tableDriver struct{}
+
+// Open - opens a connection to a registered data source
+func (d *tableDriver) Open(name string) (driver.Conn, error) {
+	if _, err := sourceTables(name); err != nil {
+		return nil, err
+	}
+	return &tableConn{source: name}, nil
+}
+
+type tableConn struct {
+	source string
+}
+
+func (c *tableConn) Prepare(query string) (driver.Stmt, error) {
+	if _, err := parseSQL(query); err != nil {
+		return nil, err
+	}
+	return &tableStmt{conn: c, query: query}, nil
+}
+
+func (c *tableConn) Close() error {
+	return nil
+}
+
+// Begin - returns a transaction that does nothing since data tables are read only through the driver
+func (c *tableConn) Begin() (driver.Tx, error) {
+	return tableTx{}, nil
+}
+
+func (c *tableConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tables, err := sourceTables(c.source)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]interface{}, len(args))
+	for _, a := range args {
+		if a.Name != "" {
+			return nil, errors.New("named arguments are not supported")
+		}
+		vals[a.Ordinal-1] = a.Value
+	}
+
+	dt, err := queryTables(query, tables, vals)
+	if err != nil {
+		return nil, err
+	}
+	return &tableRows{dt: dt}, nil
+}
+
+type tableTx struct{}
+
+func (tableTx) Commit() error {
+	return nil
+}
+
+func (tableTx) Rollback() error {
+	return nil
+}
+
+type tableStmt struct {
+	conn  *tableConn
+	query string
+}
+
+func (s *tableStmt) Close() error {
+	return nil
+}
+
+func (s *tableStmt) NumInput() int {
+	return -1
+}
+
+func (s *tableStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("data tables are read only")
+}
+
+func (s *tableStmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return s.conn.QueryContext(context.Background(), s.query, named)
+}
+
+// tableRows - reads the rows of a query result
+type tableRows struct {
+	dt  *DataTable
+	pos int
+}
+
+func (r *tableRows) Columns() []string {
+	cols := make([]string, len(r.dt.Columns))
+	for i, c := range r.dt.Columns {
+		cols[i] = c.Name
+	}
+	return cols
+}
+
+func (r *tableRows) Close() error {
+	r.pos = len(r.dt.Rows)
+	return nil
+}
+
+func (r *tableRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.dt.Rows) {
+		return io.EOF
+	}
+
+	row := &r.dt.Rows[r.pos]
+	r.pos++
+	for i := range dest {
+		var v interface{}
+		if i < len(row.Cells) {
+			v = row.Cells[i].Value
+		}
+		dest[i] = driverValue(v)
+	}
+	return nil
+}
+
+// ColumnTypeScanType - returns the Type of the column
+func (r *tableRows) ColumnTypeScanType(index int) reflect.Type {
+	if t := r.dt.Columns[index].Type; t != nil {
+		return t
+	}
+	return reflect.TypeOf((*interface{})(nil)).Elem()
+}
+
+// ColumnTypeDatabaseTypeName - returns the DBType of the column, or a name derived from its Type
+func (r *tableRows) ColumnTypeDatabaseTypeName(index int) string {
+	return dbTypeName(r.dt.Columns[index])
+}
+
+// ColumnTypeLength - returns the Length of the column if it is set
+func (r *tableRows) ColumnTypeLength(index int) (int64, bool) {
+	l := r.dt.Columns[index].Length
+	return l, l > 0
+}
+
+// driverValue - converts a cell value to one of the types a driver may return
+func driverValue(v interface{}) driver.Value {
+	switch t := v.(type) {
+	case nil, int64, float64, bool, []byte, string, time.Time:
+		return t
+	case float32:
+		return float64(t)
+	case uint64:
+		if n, ok := normalizeValue(t).(int64); ok {
+			return n
+		}
+		return fmt.Sprint(t)
+	}
+
+	if n, ok := normalizeValue(v).(int64); ok {
+		return n
+	}
+	if dv, ok := v.(driver.Valuer); ok {
+		if val, err := dv.Value(); err == nil {
+			return val
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// dbTypeName - returns the database type name of a column. The DBType is used if set,
+// otherwise a generic name is derived from the Go type
+func dbTypeName(col Column) string {
+	if col.DBType != "" {
+		return strings.ToUpper(col.DBType)
+	}
+	if col.Type == nil {
+		return ""
+	}
+
+	switch col.Type {
+	case reflect.TypeOf(time.Time{}):
+		return "DATETIME"
+	case reflect.TypeOf([]byte{}):
+		return "VARBINARY"
+	}
+
+	switch col.Type.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN"
+	case reflect.Int8, reflect.Uint8:
+		return "TINYINT"
+	case reflect.Int16, reflect.Uint16:
+		return "SMALLINT"
+	case reflect.Int32, reflect.Uint32:
+		return "INT"
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return "BIGINT"
+	case reflect.Float32:
+		return "REAL"
+	case reflect.Float64:
+		return "FLOAT"
+	case reflect.String:
+		return "VARCHAR"
+	}
+	return ""
+}
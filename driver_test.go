@@ -0,0 +1,104 @@
+package datatable
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestDriverQuery(t *testing.T) {
+	sales := NewDataTable("Sales")
+	sales.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "RegionID", Type: reflect.TypeOf(0)},
+		{Name: "Amount", Type: reflect.TypeOf([]byte{}), DBType: "DECIMAL"},
+	})
+	for _, v := range []struct {
+		id, region int
+		amount     string
+	}{{1, 1, "10.50"}, {2, 1, "20"}, {3, 2, "5"}, {4, 3, "7.25"}, {5, 2, "1"}} {
+		r := sales.NewRow()
+		r.Cells[0].Value = v.id
+		r.Cells[1].Value = v.region
+		r.Cells[2].Value = []byte(v.amount)
+		sales.AddRow(&r)
+	}
+
+	regions := NewDataTable("Regions")
+	regions.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0))},
+		{Name: "Region", Type: reflect.TypeOf(""), Length: 20},
+	})
+	for i, name := range []string{"North", "South", "East"} {
+		r := regions.NewRow()
+		r.Cells[0].Value = int64(i + 1)
+		r.Cells[1].Value = name
+		regions.AddRow(&r)
+	}
+
+	RegisterTables("drivertest", sales, regions)
+	defer UnregisterTables("drivertest")
+
+	db, err := sql.Open(DriverName, "drivertest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT s.ID, s.Amount, r.Region FROM Sales s JOIN Regions r ON r.ID = s.RegionID WHERE s.ID > ? ORDER BY s.ID", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := rows.Columns()
+	if err != nil || !reflect.DeepEqual(cols, []string{"ID", "Amount", "Region"}) {
+		t.Fatalf("unexpected columns %v %v", cols, err)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[1].DatabaseTypeName() != "DECIMAL" || types[0].DatabaseTypeName() != "BIGINT" || types[0].ScanType() != reflect.TypeOf(0) {
+		t.Fatalf("unexpected column types %s %s %v", types[1].DatabaseTypeName(), types[0].DatabaseTypeName(), types[0].ScanType())
+	}
+	if l, ok := types[2].Length(); !ok || l != 20 {
+		t.Fatalf("unexpected length %d", l)
+	}
+
+	// Read through Row.Next the same way as a database reader
+	var r Row
+	r.SetSQLRow(rows)
+	var ids []int64
+	var amounts []float64
+	for r.Next() {
+		ids = append(ids, r.ValueInt64("ID"))
+		amounts = append(amounts, r.ValueFloat64("Amount"))
+	}
+	r.Close()
+
+	if !reflect.DeepEqual(ids, []int64{3, 4, 5}) || !reflect.DeepEqual(amounts, []float64{5, 7.25, 1}) {
+		t.Fatalf("unexpected values %v %v", ids, amounts)
+	}
+
+	var count int64
+	if err := db.QueryRow("SELECT COUNT(*) FROM sales WHERE RegionID = $1", 1).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("unexpected count %d %v", count, err)
+	}
+
+	if _, err := db.Exec("DELETE FROM sales"); err == nil {
+		t.Fatal("expected Exec to fail")
+	}
+}
+
+func TestDriverUnregistered(t *testing.T) {
+	db, err := sql.Open(DriverName, "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.Ping(); err == nil {
+		t.Fatal("expected an error for an unregistered data source")
+	}
+}
@@ -0,0 +1,272 @@
+package datatable
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// A minimal FlatBuffers encoder and decoder for the Arrow IPC metadata. Tables are written
+// front to back: a vtable, then the table, then the objects the table refers to, so that
+// every offset points forward
+
+// fbNode - an object that can be referred to by an offset
+type fbNode interface {
+	writeFB(w *fbWriter) int
+}
+
+// fbField - a table field. A field with no size and no reference is absent
+type fbField struct {
+	size int
+	bits uint64
+	ref  fbNode
+}
+
+func fbUint8(v uint8) fbField { return fbField{size: 1, bits: uint64(v)} }
+func fbInt16(v int16) fbField { return fbField{size: 2, bits: uint64(uint16(v))} }
+func fbInt32(v int32) fbField { return fbField{size: 4, bits: uint64(uint32(v))} }
+func fbInt64(v int64) fbField { return fbField{size: 8, bits: uint64(v)} }
+func fbRef(n fbNode) fbField  { return fbField{size: 4, ref: n} }
+func fbBool(v bool) fbField {
+	if v {
+		return fbUint8(1)
+	}
+	return fbUint8(0)
+}
+
+// fbTable - a table with fields in field id order
+type fbTable []fbField
+
+// fbString - a string
+type fbString string
+
+// fbVector - a vector of tables or strings
+type fbVector []fbNode
+
+// fbStructs - a vector of structs of the given size and alignment
+type fbStructs struct {
+	align int
+	size  int
+	data  []byte
+}
+
+type fbWriter struct {
+	buf []byte
+}
+
+// fbBuild - returns a buffer with the root table
+func fbBuild(root fbNode) []byte {
+	w := &fbWriter{buf: make([]byte, 4)}
+	pos := root.writeFB(w)
+	binary.LittleEndian.PutUint32(w.buf, uint32(pos))
+	w.alignTo(8, 0)
+	return w.buf
+}
+
+// alignTo - pads the buffer so that the position after extra more bytes is a multiple of n
+func (w *fbWriter) alignTo(n, extra int) {
+	for (len(w.buf)+extra)%n != 0 {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+// patch - sets the offset at a position to refer to a target position
+func (w *fbWriter) patch(at, target int) {
+	binary.LittleEndian.PutUint32(w.buf[at:], uint32(target-at))
+}
+
+func (t fbTable) writeFB(w *fbWriter) int {
+	// Field offsets within the table, which starts with the offset to its vtable
+	offsets := make([]int, len(t))
+	size, align := 4, 4
+	for i, f := range t {
+		if f.size == 0 {
+			continue
+		}
+		size = (size + f.size - 1) / f.size * f.size
+		offsets[i] = size
+		size += f.size
+		align = max(align, f.size)
+	}
+
+	w.alignTo(2, 0)
+	vt := len(w.buf)
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(4+2*len(t)))
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(size))
+	for _, o := range offsets {
+		w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(o))
+	}
+
+	w.alignTo(align, 0)
+	pos := len(w.buf)
+	w.buf = append(w.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(w.buf[pos:], uint32(int32(pos-vt)))
+	for i, f := range t {
+		if f.size == 0 || f.ref != nil {
+			continue
+		}
+		at := w.buf[pos+offsets[i]:]
+		switch f.size {
+		case 1:
+			at[0] = byte(f.bits)
+		case 2:
+			binary.LittleEndian.PutUint16(at, uint16(f.bits))
+		case 4:
+			binary.LittleEndian.PutUint32(at, uint32(f.bits))
+		case 8:
+			binary.LittleEndian.PutUint64(at, f.bits)
+		}
+	}
+
+	for i, f := range t {
+		if f.ref != nil {
+			w.patch(pos+offsets[i], f.ref.writeFB(w))
+		}
+	}
+	return pos
+}
+
+func (s fbString) writeFB(w *fbWriter) int {
+	w.alignTo(4, 0)
+	pos := len(w.buf)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(len(s)))
+	w.buf = append(w.buf, s...)
+	w.buf = append(w.buf, 0)
+	return pos
+}
+
+func (v fbVector) writeFB(w *fbWriter) int {
+	w.alignTo(4, 0)
+	pos := len(w.buf)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(len(v)))
+	w.buf = append(w.buf, make([]byte, 4*len(v))...)
+	for i, n := range v {
+		w.patch(pos+4+4*i, n.writeFB(w))
+	}
+	return pos
+}
+
+func (s fbStructs) writeFB(w *fbWriter) int {
+	w.alignTo(max(s.align, 4), 4)
+	pos := len(w.buf)
+	n := 0
+	if s.size > 0 {
+		n = len(s.data) / s.size
+	}
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(n))
+	w.buf = append(w.buf, s.data...)
+	return pos
+}
+
+// fbReader - a table in a FlatBuffers buffer
+type fbReader struct {
+	buf []byte
+	pos int
+	vt  int
+}
+
+// fbRoot - returns the root table of a buffer
+func fbRoot(buf []byte) fbReader {
+	return fbTableAt(buf, int(binary.LittleEndian.Uint32(buf)))
+}
+
+func fbTableAt(buf []byte, pos int) fbReader {
+	vt := pos - int(int32(binary.LittleEndian.Uint32(buf[pos:])))
+	if vt < 0 || vt+4 > len(buf) {
+		panic(fmt.Sprintf("invalid vtable offset at %d", pos))
+	}
+	return fbReader{buf: buf, pos: pos, vt: vt}
+}
+
+// field - returns the position of a field or 0 if the field is absent
+func (r fbReader) field(id int) int {
+	vtSize := int(binary.LittleEndian.Uint16(r.buf[r.vt:]))
+	at := 4 + 2*id
+	if at+2 > vtSize {
+		return 0
+	}
+	o := int(binary.LittleEndian.Uint16(r.buf[r.vt+at:]))
+	if o == 0 {
+		return 0
+	}
+	return r.pos + o
+}
+
+func (r fbReader) uint8(id int, def uint8) uint8 {
+	if p := r.field(id); p != 0 {
+		return r.buf[p]
+	}
+	return def
+}
+
+func (r fbReader) bool(id int, def bool) bool {
+	if p := r.field(id); p != 0 {
+		return r.buf[p] != 0
+	}
+	return def
+}
+
+func (r fbReader) int16(id int, def int16) int16 {
+	if p := r.field(id); p != 0 {
+		return int16(binary.LittleEndian.Uint16(r.buf[p:]))
+	}
+	return def
+}
+
+func (r fbReader) int32(id int, def int32) int32 {
+	if p := r.field(id); p != 0 {
+		return int32(binary.LittleEndian.Uint32(r.buf[p:]))
+	}
+	return def
+}
+
+func (r fbReader) int64(id int, def int64) int64 {
+	if p := r.field(id); p != 0 {
+		return int64(binary.LittleEndian.Uint64(r.buf[p:]))
+	}
+	return def
+}
+
+// deref - returns the position an offset field refers to or 0 if the field is absent
+func (r fbReader) deref(id int) int {
+	p := r.field(id)
+	if p == 0 {
+		return 0
+	}
+	return p + int(binary.LittleEndian.Uint32(r.buf[p:]))
+}
+
+func (r fbReader) table(id int) (fbReader, bool) {
+	p := r.deref(id)
+	if p == 0 {
+		return fbReader{}, false
+	}
+	return fbTableAt(r.buf, p), true
+}
+
+func (r fbReader) string(id int) string {
+	p := r.deref(id)
+	if p == 0 {
+		return ""
+	}
+	n := int(binary.LittleEndian.Uint32(r.buf[p:]))
+	return string(r.buf[p+4 : p+4+n])
+}
+
+// vector - returns the length of a vector and the position of its first element
+func (r fbReader) vector(id int) (int, int) {
+	p := r.deref(id)
+	if p == 0 {
+		return 0, 0
+	}
+	// Every element takes at least a byte, so a longer vector does not fit the buffer
+	n := int(binary.LittleEndian.Uint32(r.buf[p:]))
+	if n > len(r.buf)-p-4 {
+		panic(fmt.Sprintf("invalid vector length at %d", p))
+	}
+	return n, p + 4
+}
+
+// vectorTable - returns the table of a vector of tables at an element position
+func (r fbReader) vectorTable(elem int) fbReader {
+	return fbTableAt(r.buf, elem+int(binary.LittleEndian.Uint32(r.buf[elem:])))
+}
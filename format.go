@@ -0,0 +1,284 @@
+package datatable
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FormatStyle - the layout written by Format
+type FormatStyle int
+
+const (
+	FormatBox      FormatStyle = iota // aligned text with box drawing borders
+	FormatASCII                       // aligned text with +, - and | borders
+	FormatMarkdown                    // a GitHub flavored Markdown table
+	FormatHTML                        // an HTML table element
+)
+
+// Limits used by String
+const (
+	stringMaxRows  = 100
+	stringMaxWidth = 40
+)
+
+// FormatOptions - options for Format
+type FormatOptions struct {
+	Style    FormatStyle
+	MaxWidth int    // maximum characters of a cell. Longer values are truncated with an ellipsis. 0 for no limit
+	MaxRows  int    // maximum rows written, followed by a line with the number of rows left out. 0 for all rows
+	Null     string // text of NULL cells. Defaults to NULL
+}
+
+// String - returns the table as a box drawn text table of at most 100 rows, with cells truncated to 40 characters
+func (dt *DataTable) String() string {
+	var sb strings.Builder
+	dt.Format(&sb, FormatOptions{MaxWidth: stringMaxWidth, MaxRows: stringMaxRows})
+	return sb.String()
+}
+
+// Format - writes the table as an aligned text, Markdown or HTML table. Columns of numeric types are right aligned
+func (dt *DataTable) Format(w io.Writer, opts FormatOptions) error {
+	if opts.Null == "" {
+		opts.Null = "NULL"
+	}
+
+	rows := len(dt.Rows)
+	if opts.MaxRows > 0 && rows > opts.MaxRows {
+		rows = opts.MaxRows
+	}
+	more := len(dt.Rows) - rows
+
+	// Cell text with header names in the first row
+	cells := make([][]string, rows+1)
+	cells[0] = make([]string, len(dt.Columns))
+	for j, col := range dt.Columns {
+		cells[0][j] = formatEscape(opts.truncate(col.Name), opts.Style)
+	}
+	for i := 0; i < rows; i++ {
+		cells[i+1] = make([]string, len(dt.Columns))
+		for j := range dt.Columns {
+			v := cellValue(&dt.Rows[i], j)
+			text := opts.Null
+			if v != nil {
+				text = formatText(v)
+			}
+			cells[i+1][j] = formatEscape(opts.truncate(text), opts.Style)
+		}
+	}
+
+	right := make([]bool, len(dt.Columns))
+	for j, col := range dt.Columns {
+		right[j] = isNumericColumn(col.Type)
+	}
+
+	bw := bufio.NewWriter(w)
+	switch opts.Style {
+	case FormatMarkdown:
+		writeMarkdown(bw, cells, right, more)
+	case FormatHTML:
+		writeHTML(bw, cells, right, more)
+	default:
+		writeTextTable(bw, cells, right, more, opts.Style == FormatASCII)
+	}
+	return bw.Flush()
+}
+
+// truncate - shortens text longer than the maximum width, ending it with an ellipsis
+func (opts FormatOptions) truncate(s string) string {
+	if opts.MaxWidth <= 0 || utf8.RuneCountInString(s) <= opts.MaxWidth {
+		return s
+	}
+
+	ellipsis := "…"
+	if opts.Style == FormatASCII {
+		ellipsis = "..."
+	}
+	keep := opts.MaxWidth - utf8.RuneCountInString(ellipsis)
+	if keep < 1 {
+		return string([]rune(s)[:opts.MaxWidth])
+	}
+	return string([]rune(s)[:keep]) + ellipsis
+}
+
+// formatText - returns the display text of a cell value
+func formatText(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []byte:
+		return "0x" + hex.EncodeToString(x)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case time.Time:
+		if x.Hour() == 0 && x.Minute() == 0 && x.Second() == 0 && x.Nanosecond() == 0 {
+			return x.Format("2006-01-02")
+		}
+		return x.Format("2006-01-02 15:04:05.999999999")
+	case *time.Time:
+		if x != nil {
+			return formatText(*x)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// formatEscape - escapes text for a style. Line breaks and tabs become spaces so that rows stay on one line
+func formatEscape(s string, style FormatStyle) string {
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(s)
+	switch style {
+	case FormatMarkdown:
+		return strings.ReplaceAll(s, "|", `\|`)
+	case FormatHTML:
+		return html.EscapeString(s)
+	}
+	return s
+}
+
+// isNumericColumn - tells if values of a column type are numbers
+func isNumericColumn(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// pad - pads text to a width on the left or right
+func pad(s string, width int, right bool) string {
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", n) + s
+	}
+	return s + strings.Repeat(" ", n)
+}
+
+// columnWidths - returns the width of the widest cell of each column, at least minWidth
+func columnWidths(cells [][]string, minWidth int) []int {
+	widths := make([]int, len(cells[0]))
+	for j := range widths {
+		widths[j] = minWidth
+		for _, row := range cells {
+			widths[j] = max(widths[j], utf8.RuneCountInString(row[j]))
+		}
+	}
+	return widths
+}
+
+func writeTextTable(w *bufio.Writer, cells [][]string, right []bool, more int, ascii bool) {
+	if len(cells[0]) > 0 {
+		widths := columnWidths(cells, 0)
+
+		// Borders as left, horizontal, junction and right runes of the top, middle and bottom lines
+		top, mid, bottom, vert := []string{"┌", "─", "┬", "┐"}, []string{"├", "─", "┼", "┤"}, []string{"└", "─", "┴", "┘"}, "│"
+		if ascii {
+			top, vert = []string{"+", "-", "+", "+"}, "|"
+			mid, bottom = top, top
+		}
+		line := func(b []string) {
+			w.WriteString(b[0])
+			for j, wd := range widths {
+				if j > 0 {
+					w.WriteString(b[2])
+				}
+				w.WriteString(strings.Repeat(b[1], wd+2))
+			}
+			w.WriteString(b[3] + "\n")
+		}
+
+		line(top)
+		for i, row := range cells {
+			w.WriteString(vert)
+			for j, c := range row {
+				w.WriteString(" " + pad(c, widths[j], right[j]) + " " + vert)
+			}
+			w.WriteString("\n")
+			if i == 0 {
+				line(mid)
+			}
+		}
+		line(bottom)
+	}
+
+	if more > 0 {
+		ellipsis := "…"
+		if ascii {
+			ellipsis = "..."
+		}
+		fmt.Fprintf(w, "%s %d more rows\n", ellipsis, more)
+	}
+}
+
+func writeMarkdown(w *bufio.Writer, cells [][]string, right []bool, more int) {
+	if len(cells[0]) > 0 {
+		widths := columnWidths(cells, 3)
+		for i, row := range cells {
+			w.WriteString("|")
+			for j, c := range row {
+				w.WriteString(" " + pad(c, widths[j], right[j]) + " |")
+			}
+			w.WriteString("\n")
+
+			if i == 0 {
+				w.WriteString("|")
+				for j, wd := range widths {
+					if right[j] {
+						w.WriteString(" " + strings.Repeat("-", wd-1) + ": |")
+					} else {
+						w.WriteString(" " + strings.Repeat("-", wd) + " |")
+					}
+				}
+				w.WriteString("\n")
+			}
+		}
+	}
+
+	if more > 0 {
+		// A blank line ends the table so the note is not read as a row
+		fmt.Fprintf(w, "\n… %d more rows\n", more)
+	}
+}
+
+func writeHTML(w *bufio.Writer, cells [][]string, right []bool, more int) {
+	w.WriteString("<table>\n<thead>\n<tr>")
+	for j, c := range cells[0] {
+		w.WriteString(htmlCell("th", c, right[j]))
+	}
+	w.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range cells[1:] {
+		w.WriteString("<tr>")
+		for j, c := range row {
+			w.WriteString(htmlCell("td", c, right[j]))
+		}
+		w.WriteString("</tr>\n")
+	}
+	w.WriteString("</tbody>\n")
+	if more > 0 {
+		fmt.Fprintf(w, "<tfoot>\n<tr><td colspan=\"%d\">… %d more rows</td></tr>\n</tfoot>\n", max(len(cells[0]), 1), more)
+	}
+	w.WriteString("</table>\n")
+}
+
+func htmlCell(tag, text string, right bool) string {
+	if right {
+		return "<" + tag + " style=\"text-align: right\">" + text + "</" + tag + ">"
+	}
+	return "<" + tag + ">" + text + "</" + tag + ">"
+}
@@ -0,0 +1,116 @@
+package datatable
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatText(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Name", Type: reflect.TypeOf("")},
+		{Name: "Price", Type: reflect.TypeOf(0.0)},
+		{Name: "Added", Type: reflect.TypeOf(time.Time{})},
+	})
+	for _, vals := range [][]interface{}{
+		{1, "Pen", 1.5, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{22, "Notebook | A4", 12.25, time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC)},
+		{333, nil, nil, nil},
+	} {
+		r := dt.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		dt.AddRow(&r)
+	}
+
+	want := `┌─────┬───────────────┬───────┬─────────────────────┐
+│  ID │ Name          │ Price │ Added               │
+├─────┼───────────────┼───────┼─────────────────────┤
+│   1 │ Pen           │   1.5 │ 2024-03-01          │
+│  22 │ Notebook | A4 │ 12.25 │ 2024-03-02 09:30:00 │
+│ 333 │ NULL          │  NULL │ NULL                │
+└─────┴───────────────┴───────┴─────────────────────┘
+`
+	if got := dt.String(); got != want {
+		t.Errorf("got\n%s\nexpected\n%s", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := dt.Format(&buf, FormatOptions{Style: FormatASCII, MaxWidth: 6, MaxRows: 2, Null: "-"}); err != nil {
+		t.Fatal(err)
+	}
+	want = `+----+--------+-------+--------+
+| ID | Name   | Price | Added  |
++----+--------+-------+--------+
+|  1 | Pen    |   1.5 | 202... |
+| 22 | Not... | 12.25 | 202... |
++----+--------+-------+--------+
+... 1 more rows
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nexpected\n%s", got, want)
+	}
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Name", Type: reflect.TypeOf("")},
+	})
+	for _, vals := range [][]interface{}{{1, "Pen"}, {22, "Notebook | A4"}, {333, nil}} {
+		r := dt.NewRow()
+		r.Cells[0].Value = vals[0]
+		r.Cells[1].Value = vals[1]
+		dt.AddRow(&r)
+	}
+
+	var buf bytes.Buffer
+	if err := dt.Format(&buf, FormatOptions{Style: FormatMarkdown, MaxRows: 2}); err != nil {
+		t.Fatal(err)
+	}
+	want := `|  ID | Name           |
+| --: | -------------- |
+|   1 | Pen            |
+|  22 | Notebook \| A4 |
+
+… 1 more rows
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nexpected\n%s", got, want)
+	}
+}
+
+func TestFormatHTML(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Name", Type: reflect.TypeOf("")},
+	})
+	for _, vals := range [][]interface{}{{1, "<b>Pen</b>\n&"}, {22, "Notebook | A4"}, {333, nil}} {
+		r := dt.NewRow()
+		r.Cells[0].Value = vals[0]
+		r.Cells[1].Value = vals[1]
+		dt.AddRow(&r)
+	}
+
+	var buf bytes.Buffer
+	if err := dt.Format(&buf, FormatOptions{Style: FormatHTML, MaxRows: 1}); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, s := range []string{
+		`<th style="text-align: right">ID</th><th>Name</th>`,
+		`<td style="text-align: right">1</td><td>&lt;b&gt;Pen&lt;/b&gt; &amp;</td>`,
+		`<tr><td colspan="2">… 2 more rows</td></tr>`,
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %s in\n%s", s, got)
+		}
+	}
+}
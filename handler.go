@@ -0,0 +1,367 @@
+package datatable
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Media types served by the table handler
+const (
+	mediaJSON = "application/json"
+	mediaCSV  = "text/csv"
+	mediaXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// Default page sizes of the table handler
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// HandlerOptions - options for NewHandler
+type HandlerOptions struct {
+	DefaultPageSize int // rows per page when the request has no size. Defaults to 50
+	MaxPageSize     int // largest size a request may ask for. Defaults to 1000
+}
+
+// NewHandler - returns an http.Handler that serves a page of the table returned by provider for each GET
+// request. The query parameters are
+//
+//	page    the 1-based page number. Defaults to 1
+//	size    the rows per page
+//	sort    comma separated column names, with a leading - to sort a column descending
+//	filter  a SQL WHERE expression over the columns, such as status = 'open' AND total > 100
+//	format  json, csv or xlsx. Overrides the Accept header
+//
+// Rows are filtered, then sorted, then paged. JSON responses hold the columns, the rows of the page as
+// objects and the page, size, total and pages counts. CSV and XLSX responses hold the rows of the page,
+// with the total in the X-Total-Count header. Invalid parameters are answered with 400 Bad Request and
+// provider errors with 500 Internal Server Error
+func NewHandler(provider func(r *http.Request) (*DataTable, error), opts HandlerOptions) http.Handler {
+	if opts.DefaultPageSize <= 0 {
+		opts.DefaultPageSize = defaultPageSize
+	}
+	if opts.MaxPageSize <= 0 {
+		opts.MaxPageSize = maxPageSize
+	}
+	opts.DefaultPageSize = min(opts.DefaultPageSize, opts.MaxPageSize)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q := r.URL.Query()
+		media := negotiateMedia(q.Get("format"), r.Header.Get("Accept"))
+		if media == "" {
+			http.Error(w, "supported formats are json, csv and xlsx", http.StatusNotAcceptable)
+			return
+		}
+
+		page, size := 1, opts.DefaultPageSize
+		var err error
+		if s := q.Get("page"); s != "" {
+			if page, err = strconv.Atoi(s); err != nil || page < 1 {
+				http.Error(w, "page must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		if s := q.Get("size"); s != "" {
+			if size, err = strconv.Atoi(s); err != nil || size < 1 || size > opts.MaxPageSize {
+				http.Error(w, fmt.Sprintf("size must be an integer from 1 to %d", opts.MaxPageSize), http.StatusBadRequest)
+				return
+			}
+		}
+
+		dt, err := provider(r)
+		if err != nil {
+			log.Printf("datatable handler: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if dt == nil {
+			dt = NewDataTable("")
+		}
+
+		rows := make([]*Row, 0, len(dt.Rows))
+		for i := range dt.Rows {
+			rows = append(rows, &dt.Rows[i])
+		}
+
+		if s := strings.TrimSpace(q.Get("filter")); s != "" {
+			match, err := dt.compileFilter(s)
+			if err != nil {
+				http.Error(w, "invalid filter: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			kept := rows[:0]
+			for _, row := range rows {
+				ok, err := match(row)
+				if err != nil {
+					http.Error(w, "invalid filter: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				if ok {
+					kept = append(kept, row)
+				}
+			}
+			rows = kept
+		}
+
+		if s := strings.TrimSpace(q.Get("sort")); s != "" {
+			cols, err := dt.parseSortColumns(strings.Split(s, ","))
+			if err != nil {
+				http.Error(w, "invalid sort: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			slices.SortStableFunc(rows, func(a, b *Row) int {
+				return compareRows(a, b, cols)
+			})
+		}
+
+		// A page past the last one is empty. Comparing before multiplying keeps large pages from overflowing
+		total := len(rows)
+		start := total
+		if page-1 <= total/size {
+			start = min((page-1)*size, total)
+		}
+		end := min(start+size, total)
+		res := dt.Clone()
+		for _, row := range rows[start:end] {
+			c := copyRow(row)
+			res.AddRow(&c)
+		}
+
+		h := w.Header()
+		if media == mediaCSV {
+			h.Set("Content-Type", media+"; charset=utf-8")
+		} else {
+			h.Set("Content-Type", media)
+		}
+		h.Set("X-Total-Count", strconv.Itoa(total))
+		if media != mediaJSON {
+			name := dt.Name
+			if name == "" {
+				name = "table"
+			}
+			ext := "csv"
+			if media == mediaXLSX {
+				ext = "xlsx"
+			}
+			h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name + "." + ext}))
+		}
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		switch media {
+		case mediaCSV:
+			err = writeCSV(w, res)
+		case mediaXLSX:
+			err = WriteXLSX(w, res)
+		default:
+			pages := 0
+			if total > 0 {
+				pages = (total + size - 1) / size
+			}
+			err = writeJSONPage(w, res, page, size, total, pages)
+		}
+		if err != nil {
+			log.Printf("datatable handler: %v", err)
+		}
+	})
+}
+
+// negotiateMedia - returns the media type of a format parameter or the supported media type the Accept header
+// prefers. Returns JSON if neither is given and an empty string if nothing supported is acceptable
+func negotiateMedia(format, accept string) string {
+	switch strings.ToLower(format) {
+	case "json":
+		return mediaJSON
+	case "csv":
+		return mediaCSV
+	case "xlsx":
+		return mediaXLSX
+	case "":
+	default:
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return mediaJSON
+	}
+
+	best, bestQ, bestExact := "", 0.0, false
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+
+		var m string
+		switch mt {
+		case mediaJSON, "application/*", "*/*":
+			m = mediaJSON
+		case mediaCSV, "text/*":
+			m = mediaCSV
+		case mediaXLSX:
+			m = mediaXLSX
+		}
+		// An exact type outranks a wildcard of the same quality
+		exact := !strings.Contains(mt, "*")
+		if m != "" && q > 0 && (q > bestQ || q == bestQ && exact && !bestExact) {
+			best, bestQ, bestExact = m, q, exact
+		}
+	}
+	return best
+}
+
+// writeCSV - writes the header and rows of a table as CSV
+func writeCSV(w io.Writer, dt *DataTable) error {
+	cw := csv.NewWriter(w)
+	rec := make([]string, len(dt.Columns))
+	for i, col := range dt.Columns {
+		rec[i] = col.Name
+	}
+	if err := cw.Write(rec); err != nil {
+		return err
+	}
+	for i := range dt.Rows {
+		for j := range dt.Columns {
+			rec[j] = ""
+			if v := cellValue(&dt.Rows[i], j); v != nil {
+				rec[j] = formatText(v)
+			}
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// jsonColumnType - returns the type name of a column in JSON responses. Columns with a numeric DBType are
+// numbers even when the driver returns their values as text
+func jsonColumnType(col Column) string {
+	t := col.Type
+	switch {
+	case isNumericDBType(col.DBType) && (t == nil || t.Kind() == reflect.String || t == reflect.TypeOf([]byte{})):
+		return "number"
+	case t == nil:
+		return "string"
+	case t == reflect.TypeOf(time.Time{}):
+		return "datetime"
+	case t == reflect.TypeOf([]byte{}):
+		return "binary"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "string"
+}
+
+// writeJSONPage - writes a page of rows as a JSON object. Rows are objects with keys in column order
+func writeJSONPage(w io.Writer, dt *DataTable, page, size, total, pages int) error {
+	bw := bufio.NewWriter(w)
+	enc := func(v interface{}) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			// Values JSON cannot hold, such as NaN, are written as null
+			b = []byte("null")
+		}
+		bw.Write(b)
+	}
+
+	bw.WriteString(`{"name":`)
+	enc(dt.Name)
+	bw.WriteString(`,"columns":[`)
+	for i, col := range dt.Columns {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(`{"name":`)
+		enc(col.Name)
+		bw.WriteString(`,"type":`)
+		enc(jsonColumnType(col))
+		bw.WriteByte('}')
+	}
+	numeric := make([]bool, len(dt.Columns))
+	for i, col := range dt.Columns {
+		numeric[i] = isNumericDBType(col.DBType)
+	}
+
+	bw.WriteString(`],"rows":[`)
+	for i := range dt.Rows {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('{')
+		for j, col := range dt.Columns {
+			if j > 0 {
+				bw.WriteByte(',')
+			}
+			enc(col.Name)
+			bw.WriteByte(':')
+			v := cellValue(&dt.Rows[i], j)
+			if n, ok := jsonNumber(v); ok && numeric[j] {
+				bw.Write(n)
+				continue
+			}
+			enc(v)
+		}
+		bw.WriteByte('}')
+	}
+	fmt.Fprintf(bw, `],"page":%d,"size":%d,"total":%d,"pages":%d}`, page, size, total, pages)
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
+
+// jsonNumber - returns the text of a number as a JSON number. Number text that is not valid JSON, such as
+// 012 or .5, is formatted again
+func jsonNumber(v interface{}) ([]byte, bool) {
+	var s string
+	switch t := v.(type) {
+	case []byte:
+		s = string(t)
+	case string:
+		s = t
+	default:
+		return nil, false
+	}
+
+	s = strings.TrimSpace(s)
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	if json.Valid([]byte(s)) {
+		return []byte(s), true
+	}
+	return strconv.AppendFloat(nil, f, 'g', -1, 64), true
+}
@@ -0,0 +1,211 @@
+package datatable
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func serveTable(t *testing.T, h http.Handler, target, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerJSON(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Status", Type: reflect.TypeOf("")},
+		{Name: "Total", Type: reflect.TypeOf(0.0)},
+	})
+	for i, v := range []struct {
+		status string
+		total  float64
+	}{{"open", 0}, {"closed", 40}, {"open", 80}, {"void", 10}, {"open", 50}, {"closed", 90}, {"open", 20}} {
+		r := dt.NewRow()
+		r.Cells[0].Value = i + 1
+		r.Cells[1].Value = v.status
+		r.Cells[2].Value = v.total
+		dt.AddRow(&r)
+	}
+	h := NewHandler(func(r *http.Request) (*DataTable, error) { return dt, nil }, HandlerOptions{})
+
+	rec := serveTable(t, h, "/?filter=status%20=%20'open'&sort=-total,id&size=3&page=1", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != mediaJSON {
+		t.Fatalf("got %d %s: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+
+	var res struct {
+		Name    string
+		Columns []struct{ Name, Type string }
+		Rows    []map[string]interface{}
+		Page    int
+		Size    int
+		Total   int
+		Pages   int
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Name != "Orders" || res.Page != 1 || res.Size != 3 || res.Total != 4 || res.Pages != 2 {
+		t.Errorf("unexpected page %+v", res)
+	}
+	if len(res.Columns) != 3 || res.Columns[0].Type != "integer" || res.Columns[1].Type != "string" || res.Columns[2].Type != "number" {
+		t.Errorf("unexpected columns %+v", res.Columns)
+	}
+
+	// Open orders are 1, 3, 5 and 7 with totals 0, 80, 50 and 20
+	var ids []float64
+	for _, row := range res.Rows {
+		ids = append(ids, row["ID"].(float64))
+	}
+	if !reflect.DeepEqual(ids, []float64{3, 5, 7}) {
+		t.Errorf("got ids %v", ids)
+	}
+	if !strings.HasPrefix(rec.Body.String(), `{"name":"Orders","columns":[{"name":"ID","type":"integer"}`) ||
+		!strings.Contains(rec.Body.String(), `{"ID":3,"Status":"open","Total":80}`) {
+		t.Errorf("unexpected body %s", rec.Body)
+	}
+
+	rec = serveTable(t, h, "/?page=9", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"rows":[],"page":9,"size":50,"total":7,"pages":1`) {
+		t.Errorf("unexpected page past the end: %s", rec.Body)
+	}
+	rec = serveTable(t, h, "/?page=9223372036854775807&size=50", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"rows":[],"page":9223372036854775807,"size":50`) {
+		t.Errorf("unexpected huge page: %d %s", rec.Code, rec.Body)
+	}
+	if dt.Rows[0].Cells[0].Value != 1 {
+		t.Error("the handler changed the provided table")
+	}
+}
+
+func TestHandlerFormats(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Status", Type: reflect.TypeOf("")},
+		{Name: "Total", Type: reflect.TypeOf(0.0)},
+	})
+	for i, v := range []struct {
+		status string
+		total  float64
+	}{{"open", 0}, {"closed", 40}, {"open", 80}, {"void", 10}, {"open", 50}, {"closed", 90}, {"open", 20}} {
+		r := dt.NewRow()
+		r.Cells[0].Value = i + 1
+		r.Cells[1].Value = v.status
+		r.Cells[2].Value = v.total
+		dt.AddRow(&r)
+	}
+	h := NewHandler(func(r *http.Request) (*DataTable, error) { return dt, nil }, HandlerOptions{DefaultPageSize: 2})
+
+	rec := serveTable(t, h, "/?sort=status,-id", "text/csv")
+	if got := rec.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Errorf("unexpected content type %s", got)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != "attachment; filename=Orders.csv" {
+		t.Errorf("unexpected disposition %s", got)
+	}
+	if got, want := rec.Body.String(), "ID,Status,Total\n6,closed,90\n2,closed,40\n"; got != want {
+		t.Errorf("got %q, expected %q", got, want)
+	}
+	if rec.Header().Get("X-Total-Count") != "7" {
+		t.Errorf("unexpected total %s", rec.Header().Get("X-Total-Count"))
+	}
+
+	rec = serveTable(t, h, "/?format=xlsx&size=10", "application/json")
+	if rec.Header().Get("Content-Type") != mediaXLSX {
+		t.Fatalf("unexpected content type %s", rec.Header().Get("Content-Type"))
+	}
+	got, err := ReadXLSX(bytes.NewReader(rec.Body.Bytes()), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Rows) != 7 || got.Name != "Orders" {
+		t.Errorf("got sheet %s with %d rows", got.Name, len(got.Rows))
+	}
+
+	for accept, want := range map[string]string{
+		"text/html, application/json;q=0.9": mediaJSON,
+		"*/*;q=0.8, text/csv;q=0.8":         mediaCSV,
+		"text/*":                            mediaCSV,
+		"application/json;q=0, text/html":   "",
+	} {
+		if got := negotiateMedia("", accept); got != want {
+			t.Errorf("%s: got %q, expected %q", accept, got, want)
+		}
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumn("Total", reflect.TypeOf(0.0), 0, "")
+	h := NewHandler(func(r *http.Request) (*DataTable, error) {
+		if r.URL.Query().Get("fail") != "" {
+			return nil, errors.New("connection refused")
+		}
+		return dt, nil
+	}, HandlerOptions{MaxPageSize: 5})
+
+	for target, code := range map[string]int{
+		"/?page=0":              http.StatusBadRequest,
+		"/?size=6":              http.StatusBadRequest,
+		"/?sort=missing":        http.StatusBadRequest,
+		"/?filter=total%20%3E":  http.StatusBadRequest,
+		"/?filter=nope%20=%201": http.StatusBadRequest,
+		"/?format=xml":          http.StatusNotAcceptable,
+		"/?fail=1":              http.StatusInternalServerError,
+	} {
+		if rec := serveTable(t, h, target, ""); rec.Code != code {
+			t.Errorf("%s: got %d, expected %d", target, rec.Code, code)
+		}
+	}
+	if rec := serveTable(t, h, "/", "image/png"); rec.Code != http.StatusNotAcceptable {
+		t.Errorf("got %d for an unsupported Accept header", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got %d for POST", rec.Code)
+	}
+}
+
+func TestHandlerDecimalBytes(t *testing.T) {
+	dt := NewDataTable("Prices")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Price", Type: reflect.TypeOf([]byte{}), DBType: "DECIMAL(10,2)"},
+	})
+	for i, p := range []string{"9.50", "100.25", "10.00", ".5"} {
+		r := dt.NewRow()
+		r.Cells[0].Value = i + 1
+		r.Cells[1].Value = []byte(p)
+		dt.AddRow(&r)
+	}
+	h := NewHandler(func(r *http.Request) (*DataTable, error) { return dt, nil }, HandlerOptions{})
+
+	// As text, 10.00 and 100.25 would sort before 9.50
+	rec := serveTable(t, h, "/?sort=-price", "")
+	want := `{"name":"Prices","columns":[{"name":"ID","type":"integer"},{"name":"Price","type":"number"}],` +
+		`"rows":[{"ID":2,"Price":100.25},{"ID":3,"Price":10.00},{"ID":1,"Price":9.50},{"ID":4,"Price":0.5}],`
+	if !strings.HasPrefix(rec.Body.String(), want) {
+		t.Errorf("unexpected body %s", rec.Body)
+	}
+	var res struct{ Rows []map[string]interface{} }
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+}
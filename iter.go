@@ -0,0 +1,101 @@
+package datatable
+
+import "iter"
+
+// Cursor - a bidirectional position over the rows of a data table
+type Cursor struct {
+	dt  *DataTable
+	pos int
+}
+
+// All - returns an iterator over the row index and a pointer to each row in the table.
+// The rows are the ones stored in the table so changes made through them are kept.
+func (dt *DataTable) All() iter.Seq2[int, *Row] {
+	return func(yield func(int, *Row) bool) {
+		for i := range dt.Rows {
+			if !yield(i, &dt.Rows[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Each - calls fn for every row in the table until fn returns false
+func (dt *DataTable) Each(fn func(index int, row *Row) bool) {
+	for i := range dt.Rows {
+		if !fn(i, &dt.Rows[i]) {
+			return
+		}
+	}
+}
+
+// Chunk - returns an iterator over batches of at most size rows. The last batch may be smaller.
+func (dt *DataTable) Chunk(size int) iter.Seq[[]*Row] {
+	return func(yield func([]*Row) bool) {
+		if size <= 0 {
+			return
+		}
+
+		for start := 0; start < len(dt.Rows); start += size {
+			end := min(start+size, len(dt.Rows))
+			batch := make([]*Row, 0, end-start)
+			for i := start; i < end; i++ {
+				batch = append(batch, &dt.Rows[i])
+			}
+			if !yield(batch) {
+				return
+			}
+		}
+	}
+}
+
+// NewCursor - returns a cursor positioned before the first row
+func (dt *DataTable) NewCursor() *Cursor {
+	return &Cursor{dt: dt, pos: -1}
+}
+
+// Next - moves to the next row. Returns false when there are no more rows
+func (c *Cursor) Next() bool {
+	if c.pos < len(c.dt.Rows) {
+		c.pos++
+	}
+	return c.pos < len(c.dt.Rows)
+}
+
+// Prev - moves to the previous row. Returns false when moved before the first row
+func (c *Cursor) Prev() bool {
+	if c.pos >= len(c.dt.Rows) {
+		c.pos = len(c.dt.Rows)
+	}
+	if c.pos >= 0 {
+		c.pos--
+	}
+	return c.pos >= 0
+}
+
+// Seek - moves to the row at the index. Returns false and keeps the position if the index is out of range
+func (c *Cursor) Seek(index int) bool {
+	if index < 0 || index >= len(c.dt.Rows) {
+		return false
+	}
+	c.pos = index
+	return true
+}
+
+// Position - returns the current row index. It is -1 before the first row and RowCount after the last
+func (c *Cursor) Position() int {
+	return c.pos
+}
+
+// Row - returns a pointer to the current row or nil if the cursor is not on a row
+func (c *Cursor) Row() *Row {
+	if c.pos < 0 || c.pos >= len(c.dt.Rows) {
+		return nil
+	}
+	return &c.dt.Rows[c.pos]
+}
+
+// Reset - moves the cursor before the first row
+func (c *Cursor) Reset() {
+	c.pos = -1
+}
@@ -0,0 +1,96 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllYieldsTableRows(t *testing.T) {
+	dt := NewDataTable("Iter")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	dt.AddColumn("Code", reflect.TypeOf(""), 12, "")
+	for i := 0; i < 3; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		dt.AddRow(&r)
+	}
+
+	for i, r := range dt.All() {
+		r.Cells[1].Value = "Changed"
+		if r.Cells[0].Value != i {
+			t.Errorf("expected ID %d, got %v", i, r.Cells[0].Value)
+		}
+	}
+
+	for _, r := range dt.Rows {
+		if r.ValueString("Code") != "Changed" {
+			t.Errorf("expected the table row to be changed, got %s", r.ValueString("Code"))
+		}
+	}
+
+	n := 0
+	dt.Each(func(index int, row *Row) bool {
+		n++
+		return index < 1
+	})
+	if n != 2 {
+		t.Errorf("expected Each to stop after 2 rows, got %d", n)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	dt := NewDataTable("Chunk")
+	dt.AddColumn("Code", reflect.TypeOf(""), 12, "")
+	for i := 0; i < 7; i++ {
+		r := dt.NewRow()
+		dt.AddRow(&r)
+	}
+
+	var sizes []int
+	for batch := range dt.Chunk(3) {
+		sizes = append(sizes, len(batch))
+		batch[0].Cells[0].Value = "First"
+	}
+
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[2] != 1 {
+		t.Errorf("unexpected chunk sizes %v", sizes)
+	}
+	if dt.Rows[6].ValueString("Code") != "First" {
+		t.Error("expected chunk rows to point to the table rows")
+	}
+}
+
+func TestCursor(t *testing.T) {
+	dt := NewDataTable("Cursor")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	for i := 0; i < 3; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		dt.AddRow(&r)
+	}
+	c := dt.NewCursor()
+
+	if c.Row() != nil || c.Position() != -1 {
+		t.Error("expected the cursor to start before the first row")
+	}
+
+	n := 0
+	for c.Next() {
+		n++
+	}
+	if n != 3 || c.Position() != 3 {
+		t.Errorf("expected 3 rows and position 3, got %d and %d", n, c.Position())
+	}
+
+	if !c.Prev() || c.Row().ValueInt("ID") != 2 {
+		t.Error("expected Prev to move to the last row")
+	}
+
+	if c.Seek(5) || c.Position() != 2 {
+		t.Error("expected Seek out of range to fail and keep the position")
+	}
+
+	if !c.Seek(0) || c.Prev() || c.Position() != -1 {
+		t.Error("expected Prev from the first row to move before it")
+	}
+}
@@ -0,0 +1,119 @@
+package datatable
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MissingSchemaAction - what Merge does with source columns that are not in the target table
+type MissingSchemaAction int
+
+// Missing schema actions
+const (
+	MissingSchemaAdd    MissingSchemaAction = iota // add the missing columns to the target table
+	MissingSchemaIgnore                            // ignore the values of the missing columns
+	MissingSchemaError                             // fail the merge
+)
+
+// MergeOptions - options for Merge
+type MergeOptions struct {
+	KeyColumns          []string            // columns that identify a row. The target's primary key is used if empty
+	MissingSchemaAction MissingSchemaAction // action for source columns that are not in the target
+	PreserveChanges     bool                // keep the values of existing target rows and only fill their null cells
+	DeleteMissing       bool                // remove target rows whose key is not in the source
+}
+
+// Merge - applies the rows of src to the table. Rows with a key already in the table are updated,
+// other rows are added and, if DeleteMissing is set, rows not in src are removed
+func (dt *DataTable) Merge(src *DataTable, opts MergeOptions) error {
+	keys := opts.KeyColumns
+	if len(keys) == 0 {
+		keys = dt.PrimaryKey()
+	}
+	if len(keys) == 0 {
+		return errors.New("merge requires key columns or a primary key")
+	}
+
+	dstKey := make([]int, len(keys))
+	srcKey := make([]int, len(keys))
+	for i, k := range keys {
+		if dstKey[i] = dt.columnIndex(k); dstKey[i] == -1 {
+			return fmt.Errorf("key column %s does not exist in table %s", k, dt.Name)
+		}
+		if srcKey[i] = src.columnIndex(k); srcKey[i] == -1 {
+			return fmt.Errorf("key column %s does not exist in table %s", k, src.Name)
+		}
+	}
+
+	// Map source columns to target columns, adding columns if allowed
+	colmap := make([]int, len(src.Columns))
+	for i, col := range src.Columns {
+		colmap[i] = dt.columnIndex(col.Name)
+		if colmap[i] != -1 {
+			continue
+		}
+
+		switch opts.MissingSchemaAction {
+		case MissingSchemaAdd:
+			col.PrimaryKey = false
+			dt.AddColumns([]Column{col})
+			colmap[i] = len(dt.Columns) - 1
+		case MissingSchemaError:
+			return fmt.Errorf("column %s does not exist in table %s", col.Name, dt.Name)
+		}
+	}
+
+	existing := make(map[string]int, len(dt.Rows))
+	for i := range dt.Rows {
+		existing[rowKeyString(&dt.Rows[i], dstKey)] = i
+	}
+
+	seen := make(map[string]bool, len(src.Rows))
+	for i := range src.Rows {
+		sr := &src.Rows[i]
+		k := rowKeyString(sr, srcKey)
+		seen[k] = true
+
+		if idx, ok := existing[k]; ok {
+			dr := &dt.Rows[idx]
+			for c, d := range colmap {
+				if d == -1 || c >= len(sr.Cells) {
+					continue
+				}
+				if opts.PreserveChanges && dr.Cells[d].Value != nil {
+					continue
+				}
+				dr.Cells[d].Value = copyValue(sr.Cells[c].Value)
+			}
+			continue
+		}
+
+		nr := dt.NewRow()
+		for c, d := range colmap {
+			if d != -1 && c < len(sr.Cells) {
+				nr.Cells[d].Value = copyValue(sr.Cells[c].Value)
+			}
+		}
+		dt.AddRow(&nr)
+		existing[k] = dt.RowCount - 1
+	}
+
+	if opts.DeleteMissing {
+		dt.RemoveWhere(func(row *Row) bool {
+			return !seen[rowKeyString(row, dstKey)]
+		})
+	}
+
+	return nil
+}
+
+// rowKeyString - builds a map key from the cells at the column indexes
+func rowKeyString(row *Row, cols []int) string {
+	values := make([]interface{}, len(cols))
+	for i, c := range cols {
+		if c >= 0 && c < len(row.Cells) {
+			values[i] = row.Cells[c].Value
+		}
+	}
+	return keyString(values...)
+}
@@ -0,0 +1,99 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	dt := NewDataTable("Cache")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0)), PrimaryKey: true},
+		{Name: "Name", Type: reflect.TypeOf(""), Length: 20},
+	})
+	for i, name := range []string{"One", "Two", "Three"} {
+		r := dt.NewRow()
+		r.Cells[0].Value = int64(i + 1)
+		r.Cells[1].Value = name
+		dt.AddRow(&r)
+	}
+
+	src := NewDataTable("Delta")
+	src.AddColumns([]Column{
+		{Name: "id", Type: reflect.TypeOf(0)},
+		{Name: "NAME", Type: reflect.TypeOf([]byte{})},
+		{Name: "Price", Type: reflect.TypeOf(0.0)},
+	})
+	for _, v := range []struct {
+		id    int
+		name  string
+		price float64
+	}{{2, "Deux", 2.5}, {4, "Four", 4.5}} {
+		r := src.NewRow()
+		r.Cells[0].Value = v.id
+		r.Cells[1].Value = []byte(v.name)
+		r.Cells[2].Value = v.price
+		src.AddRow(&r)
+	}
+
+	if err := dt.Merge(src, MergeOptions{DeleteMissing: true}); err != nil {
+		t.Fatal(err)
+	}
+	checkRowIndexes(t, dt)
+
+	if dt.ColumnCount != 3 || dt.RowCount != 2 {
+		t.Fatalf("expected 3 columns and 2 rows, got %d and %d", dt.ColumnCount, dt.RowCount)
+	}
+	if dt.Rows[0].ValueString("Name") != "Deux" || dt.Rows[0].ValueFloat64("Price") != 2.5 {
+		t.Fatalf("expected row 2 to be updated, got %v", dt.Rows[0].Cells)
+	}
+	if dt.Rows[1].ValueInt("ID") != 4 {
+		t.Fatalf("expected row 4 to be added, got %v", dt.Rows[1].Cells)
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	dt := NewDataTable("Cache")
+	src := NewDataTable("Delta")
+	for _, tbl := range []*DataTable{dt, src} {
+		tbl.AddColumns([]Column{
+			{Name: "ID", Type: reflect.TypeOf(int64(0)), PrimaryKey: true},
+			{Name: "Name", Type: reflect.TypeOf(""), Length: 20},
+		})
+	}
+	src.AddColumn("Extra", reflect.TypeOf(""), 5, "")
+	for i, v := range [][2]interface{}{{"One", "Uno"}, {nil, "Dos"}} {
+		r := dt.NewRow()
+		r.Cells[0].Value = int64(i + 1)
+		r.Cells[1].Value = v[0]
+		dt.AddRow(&r)
+
+		r = src.NewRow()
+		r.Cells[0].Value = int64(i + 1)
+		r.Cells[1].Value = v[1]
+		src.AddRow(&r)
+	}
+
+	if err := dt.Merge(src, MergeOptions{MissingSchemaAction: MissingSchemaError}); err == nil {
+		t.Fatal("expected an error for the missing column")
+	}
+
+	if err := dt.Merge(src, MergeOptions{MissingSchemaAction: MissingSchemaIgnore, PreserveChanges: true}); err != nil {
+		t.Fatal(err)
+	}
+	if dt.ColumnCount != 2 {
+		t.Fatal("expected the extra column to be ignored")
+	}
+	if dt.Rows[0].ValueString("Name") != "One" || dt.Rows[1].ValueString("Name") != "Dos" {
+		t.Fatal("expected only null cells to be filled")
+	}
+
+	nokey := NewDataTable("NoKey")
+	nokey.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	if err := nokey.Merge(src, MergeOptions{}); err == nil {
+		t.Fatal("expected an error without key columns")
+	}
+	if err := nokey.Merge(src, MergeOptions{KeyColumns: []string{"ID"}}); err != nil || nokey.RowCount != 2 {
+		t.Fatalf("expected the key columns option to be used, got %v", err)
+	}
+}
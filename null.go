@@ -0,0 +1,152 @@
+package datatable
+
+import "database/sql"
+
+// IsNull - returns true if the value of the column is null or the row has no such column
+func (rw *Row) IsNull(col string) bool {
+	return rw.ValueByName(&col) == nil
+}
+
+// Coalesce - returns the first non-null value of the columns, or nil if all are null
+func (rw *Row) Coalesce(cols ...string) interface{} {
+	for _, col := range cols {
+		if v := rw.ValueByName(&col); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// ValueNullString - returns the value as sql.NullString. Values that are not strings are formatted as text
+func (rw *Row) ValueNullString(col string) sql.NullString {
+	v := rw.ValueByName(&col)
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: columnarStringValue(v), Valid: true}
+}
+
+// ValueNullInt64 - returns the value as sql.NullInt64. Valid is false if the value is null or cannot be
+// converted to an integer
+func (rw *Row) ValueNullInt64(col string) sql.NullInt64 {
+	v := rw.ValueByName(&col)
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	n, err := columnarInt64Value(v)
+	return sql.NullInt64{Int64: n, Valid: err == nil}
+}
+
+// ValueNullFloat64 - returns the value as sql.NullFloat64. Valid is false if the value is null or cannot be
+// converted to a number
+func (rw *Row) ValueNullFloat64(col string) sql.NullFloat64 {
+	v := rw.ValueByName(&col)
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	f, err := columnarFloat64Value(v)
+	return sql.NullFloat64{Float64: f, Valid: err == nil}
+}
+
+// ValueNullTime - returns the value as sql.NullTime. Strings are parsed as RFC 3339. Valid is false if the
+// value is null or cannot be converted to a time
+func (rw *Row) ValueNullTime(col string) sql.NullTime {
+	v := rw.ValueByName(&col)
+	if v == nil {
+		return sql.NullTime{}
+	}
+	t, err := columnarTimeValue(v)
+	return sql.NullTime{Time: t, Valid: err == nil}
+}
+
+// ValueNullBool - returns the value as sql.NullBool. Values are converted as in ValueBool
+func (rw *Row) ValueNullBool(col string) sql.NullBool {
+	b := rw.ValuePtrBool(col)
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
+
+// FillNull - sets the null cells of the column to the value and returns the number of cells set,
+// or -1 if the column does not exist
+func (dt *DataTable) FillNull(col string, value interface{}) int {
+	idx := dt.columnIndex(col)
+	if idx == -1 {
+		return -1
+	}
+
+	n := 0
+	for i := range dt.Rows {
+		r := &dt.Rows[i]
+		if idx < len(r.Cells) && r.Cells[idx].Value == nil {
+			r.Cells[idx].Value = copyValue(value)
+			n++
+		}
+	}
+	return n
+}
+
+// DropNulls - removes the rows with a null value in any of the columns, or in any column if none are named.
+// Returns the number of rows removed, or -1 without removing any if a column does not exist
+func (dt *DataTable) DropNulls(cols ...string) int {
+	idx, ok := dt.distinctColumns(cols)
+	if !ok {
+		return -1
+	}
+
+	return dt.RemoveWhere(func(row *Row) bool {
+		for _, c := range idx {
+			if c >= len(row.Cells) || row.Cells[c].Value == nil {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FillForward - sets each null cell of the columns, or of every column if none are named, to the last
+// non-null value above it. Nulls before the first value are kept. Returns the number of cells set, or -1
+// if a column does not exist
+func (dt *DataTable) FillForward(cols ...string) int {
+	return dt.fillNulls(cols, false)
+}
+
+// FillBackward - sets each null cell of the columns, or of every column if none are named, to the next
+// non-null value below it. Nulls after the last value are kept. Returns the number of cells set, or -1
+// if a column does not exist
+func (dt *DataTable) FillBackward(cols ...string) int {
+	return dt.fillNulls(cols, true)
+}
+
+// fillNulls - fills null cells from the nearest value above, or below if backward is set
+func (dt *DataTable) fillNulls(cols []string, backward bool) int {
+	idx, ok := dt.distinctColumns(cols)
+	if !ok {
+		return -1
+	}
+
+	n := 0
+	for _, c := range idx {
+		var last interface{}
+		for k := range dt.Rows {
+			i := k
+			if backward {
+				i = len(dt.Rows) - 1 - k
+			}
+			r := &dt.Rows[i]
+			if c >= len(r.Cells) {
+				continue
+			}
+			if r.Cells[c].Value != nil {
+				last = r.Cells[c].Value
+				continue
+			}
+			if last != nil {
+				r.Cells[c].Value = copyValue(last)
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,161 @@
+package datatable
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// columnValues - returns the values of a column
+func columnValues(dt *DataTable, col int) []interface{} {
+	var values []interface{}
+	for _, r := range dt.Rows {
+		values = append(values, r.Cells[col].Value)
+	}
+	return values
+}
+
+func TestRowNullAccessors(t *testing.T) {
+	dt := NewDataTable("Types")
+	dt.AddColumns([]Column{
+		{Name: "S", Type: reflect.TypeOf("")},
+		{Name: "I", Type: reflect.TypeOf(0)},
+		{Name: "F", Type: reflect.TypeOf(0.0)},
+		{Name: "T", Type: reflect.TypeOf(time.Time{})},
+		{Name: "B", Type: reflect.TypeOf(false)},
+	})
+	when := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := dt.NewRow()
+	r.Cells[0].Value, r.Cells[1].Value, r.Cells[2].Value, r.Cells[3].Value, r.Cells[4].Value = []byte("text"), int32(0), 1.5, when, "yes"
+	dt.AddRow(&r)
+	e := dt.NewRow()
+	dt.AddRow(&e)
+
+	full, empty := &dt.Rows[0], &dt.Rows[1]
+	if full.IsNull("i") || !empty.IsNull("I") || !full.IsNull("Missing") {
+		t.Error("unexpected IsNull result")
+	}
+	if got := full.ValueNullString("S"); got != (sql.NullString{String: "text", Valid: true}) {
+		t.Errorf("got %v", got)
+	}
+	if got := full.ValueNullInt64("I"); got != (sql.NullInt64{Int64: 0, Valid: true}) {
+		t.Errorf("got %v", got)
+	}
+	if got := full.ValueNullFloat64("F"); got != (sql.NullFloat64{Float64: 1.5, Valid: true}) {
+		t.Errorf("got %v", got)
+	}
+	if got := full.ValueNullTime("T"); got != (sql.NullTime{Time: when, Valid: true}) {
+		t.Errorf("got %v", got)
+	}
+	if got := full.ValueNullBool("B"); got != (sql.NullBool{Bool: true, Valid: true}) {
+		t.Errorf("got %v", got)
+	}
+	if got := full.ValueNullInt64("S"); got.Valid {
+		t.Errorf("expected text to be an invalid integer, got %v", got)
+	}
+
+	if empty.ValueNullString("S").Valid || empty.ValueNullInt64("I").Valid || empty.ValueNullFloat64("F").Valid ||
+		empty.ValueNullTime("T").Valid || empty.ValueNullBool("B").Valid {
+		t.Error("expected null values to be invalid")
+	}
+
+	if got := empty.Coalesce("S", "I"); got != nil {
+		t.Errorf("got %v", got)
+	}
+	if got := full.Coalesce("Missing", "I", "S"); got != int32(0) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestFillNull(t *testing.T) {
+	dt := NewDataTable("Readings")
+	dt.AddColumn("Value", reflect.TypeOf(0), 0, "")
+	for _, v := range []interface{}{nil, 10, nil, 0, nil} {
+		r := dt.NewRow()
+		r.Cells[0].Value = v
+		dt.AddRow(&r)
+	}
+
+	if n := dt.FillNull("value", -1); n != 3 {
+		t.Errorf("filled %d cells, expected 3", n)
+	}
+	if got, want := columnValues(dt, 0), []interface{}{-1, 10, -1, 0, -1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+	if n := dt.FillNull("Missing", 0); n != -1 {
+		t.Errorf("got %d for a missing column", n)
+	}
+}
+
+func TestDropNulls(t *testing.T) {
+	src := NewDataTable("Readings")
+	src.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Value", Type: reflect.TypeOf(0)},
+		{Name: "Note", Type: reflect.TypeOf("")},
+	})
+	for _, vals := range [][]interface{}{{1, nil, "a"}, {2, 10, nil}, {3, nil, nil}, {4, 0, "d"}, {5, nil, nil}} {
+		r := src.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		src.AddRow(&r)
+	}
+
+	dt := src.Copy()
+	if n := dt.DropNulls("Note"); n != 3 {
+		t.Errorf("removed %d rows, expected 3", n)
+	}
+	checkRowIDs(t, dt, 1, 4)
+
+	dt = src.Copy()
+	if n := dt.DropNulls(); n != 4 {
+		t.Errorf("removed %d rows, expected 4", n)
+	}
+	checkRowIDs(t, dt, 4)
+
+	if n := dt.DropNulls("Missing"); n != -1 || len(dt.Rows) != 1 {
+		t.Errorf("got %d for a missing column", n)
+	}
+}
+
+func TestFillForwardBackward(t *testing.T) {
+	src := NewDataTable("Readings")
+	src.AddColumns([]Column{
+		{Name: "Value", Type: reflect.TypeOf(0)},
+		{Name: "Note", Type: reflect.TypeOf("")},
+	})
+	for _, vals := range [][]interface{}{{nil, "a"}, {10, nil}, {nil, nil}, {0, "d"}, {nil, nil}} {
+		r := src.NewRow()
+		r.Cells[0].Value, r.Cells[1].Value = vals[0], vals[1]
+		src.AddRow(&r)
+	}
+
+	dt := src.Copy()
+	if n := dt.FillForward("Value", "Note"); n != 5 {
+		t.Errorf("filled %d cells, expected 5", n)
+	}
+	if got, want := columnValues(dt, 0), []interface{}{nil, 10, 10, 0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+	if got, want := columnValues(dt, 1), []interface{}{"a", "a", "a", "d", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+
+	dt = src.Copy()
+	if n := dt.FillBackward(); n != 4 {
+		t.Errorf("filled %d cells, expected 4", n)
+	}
+	if got, want := columnValues(dt, 0), []interface{}{10, 10, 0, 0, nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+	if got, want := columnValues(dt, 1), []interface{}{"a", "d", "d", "d", nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+
+	if n := dt.FillForward("Missing"); n != -1 {
+		t.Errorf("got %d for a missing column", n)
+	}
+}
@@ -0,0 +1,117 @@
+package datatable
+
+// Slice - returns a new table with the same columns and copies of the rows from start up to but not
+// including end. The range is clamped to the rows of the table and rows are renumbered from 0
+func (dt *DataTable) Slice(start, end int) *DataTable {
+	start = max(start, 0)
+	end = min(end, len(dt.Rows))
+
+	c := dt.Clone()
+	if start >= end {
+		return c
+	}
+	rows := make([]Row, 0, end-start)
+	for i := start; i < end; i++ {
+		rows = append(rows, copyRow(&dt.Rows[i]))
+	}
+	c.AddRows(rows)
+	return c
+}
+
+// Head - returns a new table with copies of the first n rows
+func (dt *DataTable) Head(n int) *DataTable {
+	return dt.Slice(0, max(n, 0))
+}
+
+// Tail - returns a new table with copies of the last n rows
+func (dt *DataTable) Tail(n int) *DataTable {
+	return dt.Slice(len(dt.Rows)-max(n, 0), len(dt.Rows))
+}
+
+// Page - returns a new table with copies of the rows of a page. Pages are numbered from 1. A page past the
+// last one, or an invalid page number or size, returns a table with no rows
+func (dt *DataTable) Page(pageNum, pageSize int) *DataTable {
+	if pageNum < 1 || pageSize < 1 || pageNum-1 > len(dt.Rows)/pageSize {
+		return dt.Clone()
+	}
+	start := (pageNum - 1) * pageSize
+	return dt.Slice(start, start+pageSize)
+}
+
+// Paginator - moves through the pages of a table. The table is read when a page is taken, so rows
+// added or removed in between are reflected in the counts
+type Paginator struct {
+	dt       *DataTable
+	pageSize int
+	page     int
+}
+
+// NewPaginator - returns a paginator positioned before the first page. A page size below 1 uses 50 rows
+func NewPaginator(dt *DataTable, pageSize int) *Paginator {
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	return &Paginator{dt: dt, pageSize: pageSize}
+}
+
+// PageSize - returns the number of rows per page
+func (p *Paginator) PageSize() int {
+	return p.pageSize
+}
+
+// TotalRows - returns the number of rows in the table
+func (p *Paginator) TotalRows() int {
+	return len(p.dt.Rows)
+}
+
+// TotalPages - returns the number of pages. A table with no rows has no pages
+func (p *Paginator) TotalPages() int {
+	return (len(p.dt.Rows) + p.pageSize - 1) / p.pageSize
+}
+
+// PageNum - returns the current page number, or 0 before the first page
+func (p *Paginator) PageNum() int {
+	return p.page
+}
+
+// HasNext - returns true if there is a page after the current one
+func (p *Paginator) HasNext() bool {
+	return p.page < p.TotalPages()
+}
+
+// HasPrev - returns true if there is a page before the current one
+func (p *Paginator) HasPrev() bool {
+	return p.page > 1
+}
+
+// Next - moves to the next page. Returns false if there is none
+func (p *Paginator) Next() bool {
+	if !p.HasNext() {
+		return false
+	}
+	p.page++
+	return true
+}
+
+// Prev - moves to the previous page. Returns false if there is none
+func (p *Paginator) Prev() bool {
+	if !p.HasPrev() {
+		return false
+	}
+	p.page--
+	return true
+}
+
+// SetPage - moves to a page. Returns false and keeps the position if the page does not exist
+func (p *Paginator) SetPage(pageNum int) bool {
+	if pageNum < 1 || pageNum > p.TotalPages() {
+		return false
+	}
+	p.page = pageNum
+	return true
+}
+
+// Current - returns a new table with copies of the rows of the current page
+func (p *Paginator) Current() *DataTable {
+	return p.dt.Page(p.page, p.pageSize)
+}
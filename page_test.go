@@ -0,0 +1,109 @@
+package datatable
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// checkRowIDs - checks the ID values of a table and that rows are numbered from 0
+func checkRowIDs(t *testing.T, dt *DataTable, ids ...int) {
+	t.Helper()
+	if len(dt.Rows) != len(ids) || dt.RowCount != len(ids) {
+		t.Fatalf("got %d rows (RowCount %d), expected %d", len(dt.Rows), dt.RowCount, len(ids))
+	}
+	for i, id := range ids {
+		if v := dt.Rows[i].Value("id"); v != id {
+			t.Errorf("row %d: got ID %v, expected %d", i, v, id)
+		}
+		for _, c := range dt.Rows[i].Cells {
+			if c.RowIndex != i {
+				t.Errorf("row %d: cell %s has RowIndex %d", i, c.ColumnName, c.RowIndex)
+			}
+		}
+	}
+}
+
+func TestSlice(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	dt.AddColumn("Code", reflect.TypeOf(""), 12, "")
+	for i := 0; i < 10; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		r.Cells[1].Value = "Code" + strconv.Itoa(i)
+		dt.AddRow(&r)
+	}
+
+	s := dt.Slice(3, 6)
+	checkRowIDs(t, s, 3, 4, 5)
+	if s.Name != "Items" || len(s.Columns) != 2 {
+		t.Errorf("unexpected schema %s %v", s.Name, s.Columns)
+	}
+
+	s.Rows[0].Cells[1].Value = "Changed"
+	if dt.Rows[3].Cells[1].Value != "Code3" {
+		t.Error("changing the slice changed the source table")
+	}
+
+	checkRowIDs(t, dt.Slice(-5, 2), 0, 1)
+	checkRowIDs(t, dt.Slice(8, 100), 8, 9)
+	checkRowIDs(t, dt.Slice(6, 3))
+	checkRowIDs(t, dt.Head(2), 0, 1)
+	checkRowIDs(t, dt.Head(-1))
+	checkRowIDs(t, dt.Tail(3), 7, 8, 9)
+	checkRowIDs(t, dt.Tail(20), 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+}
+
+func TestPage(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	for i := 0; i < 10; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		dt.AddRow(&r)
+	}
+
+	checkRowIDs(t, dt.Page(1, 4), 0, 1, 2, 3)
+	checkRowIDs(t, dt.Page(3, 4), 8, 9)
+	checkRowIDs(t, dt.Page(4, 4))
+	checkRowIDs(t, dt.Page(0, 4))
+	checkRowIDs(t, dt.Page(1, 0))
+	checkRowIDs(t, dt.Page(1<<62, 1<<62))
+}
+
+func TestPaginator(t *testing.T) {
+	dt := NewDataTable("Items")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	for i := 0; i < 10; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		dt.AddRow(&r)
+	}
+	p := NewPaginator(dt, 4)
+
+	if p.TotalPages() != 3 || p.TotalRows() != 10 || p.PageNum() != 0 || p.HasPrev() {
+		t.Fatalf("unexpected paginator state: %d pages, %d rows, page %d", p.TotalPages(), p.TotalRows(), p.PageNum())
+	}
+
+	var sizes []int
+	for p.Next() {
+		sizes = append(sizes, len(p.Current().Rows))
+	}
+	if len(sizes) != 3 || sizes[0] != 4 || sizes[2] != 2 || p.HasNext() || p.PageNum() != 3 {
+		t.Errorf("got page sizes %v ending at page %d", sizes, p.PageNum())
+	}
+
+	if !p.Prev() || p.PageNum() != 2 || !p.HasNext() {
+		t.Errorf("expected to move back to page 2, at %d", p.PageNum())
+	}
+	checkRowIDs(t, p.Current(), 4, 5, 6, 7)
+
+	if p.SetPage(4) || !p.SetPage(1) || p.Prev() {
+		t.Error("unexpected SetPage or Prev result")
+	}
+
+	if p := NewPaginator(NewDataTable("Empty"), 0); p.TotalPages() != 0 || p.Next() || p.PageSize() != 50 {
+		t.Error("an empty table should have no pages")
+	}
+}
@@ -0,0 +1,856 @@
+package datatable
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+)
+
+// Parquet constants from parquet.thrift
+const (
+	parquetMagic = "PAR1"
+
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetInt96     = 3
+	parquetFloat     = 4
+	parquetDouble    = 5
+	parquetByteArray = 6
+	parquetFixedLen  = 7
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetConvertedUTF8      = 0
+	parquetConvertedEnum      = 4
+	parquetConvertedDecimal   = 5
+	parquetConvertedDate      = 6
+	parquetConvertedMillis    = 9
+	parquetConvertedMicros    = 10
+	parquetConvertedJSON      = 19
+	parquetLogicalString      = 1
+	parquetLogicalEnum        = 4
+	parquetLogicalDecimal     = 5
+	parquetLogicalDate        = 6
+	parquetLogicalTimestamp   = 8
+	parquetLogicalJSON        = 12
+	parquetEncodingPlain      = 0
+	parquetEncodingPlainDict  = 2
+	parquetEncodingRLE        = 3
+	parquetEncodingRLEDict    = 8
+	parquetPageData           = 0
+	parquetPageIndex          = 1
+	parquetPageDictionary     = 2
+	parquetPageDataV2         = 3
+	parquetCodecUncompressed  = 0
+	parquetCodecSnappy        = 1
+	parquetCodecGzip          = 2
+	parquetMaxDecimalInt64Len = 18
+)
+
+// ParquetCompression - the compression of Parquet pages
+type ParquetCompression int
+
+// Parquet compressions
+const (
+	ParquetUncompressed ParquetCompression = iota
+	ParquetGzip
+)
+
+// ParquetOptions - options for WriteParquet
+type ParquetOptions struct {
+	RowGroupSize int // rows per row group. Defaults to 65536
+	Compression  ParquetCompression
+}
+
+// parquetChunk - a column chunk written to a row group
+type parquetChunk struct {
+	offset       int64
+	values       int64
+	uncompressed int64
+	compressed   int64
+}
+
+// WriteParquet - writes the table as a Parquet file with the same type mapping as WriteArrow. DECIMAL columns
+// with a precision up to 18 are stored as INT64, and as 16 byte fixed length arrays otherwise. Columns that
+// allow NULL are optional. Rows are written in row groups so that only one group is held in memory at a time
+func (dt *DataTable) WriteParquet(w io.Writer, opts ParquetOptions) error {
+	fields, err := dt.columnarFields()
+	if err != nil {
+		return err
+	}
+	group := opts.RowGroupSize
+	if group <= 0 {
+		group = defaultBatchSize
+	}
+	codec := int32(parquetCodecUncompressed)
+	if opts.Compression == ParquetGzip {
+		codec = parquetCodecGzip
+	}
+
+	cw := &countingWriter{w: w}
+	if _, err := cw.Write([]byte(parquetMagic)); err != nil {
+		return err
+	}
+
+	var groups [][]parquetChunk
+	var groupRows []int
+	for start := 0; start < len(dt.Rows); start += group {
+		end := min(start+group, len(dt.Rows))
+		chunks := make([]parquetChunk, len(fields))
+		for i, f := range fields {
+			page, err := dt.parquetPage(f, start, end)
+			if err != nil {
+				return err
+			}
+
+			data := page
+			if codec == parquetCodecGzip {
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				zw.Write(page)
+				if err := zw.Close(); err != nil {
+					return err
+				}
+				data = buf.Bytes()
+			}
+
+			th := newThriftWriter()
+			th.i32(1, parquetPageData)
+			th.i32(2, int32(len(page)))
+			th.i32(3, int32(len(data)))
+			th.beginStruct(5)
+			th.i32(1, int32(end-start))
+			th.i32(2, parquetEncodingPlain)
+			th.i32(3, parquetEncodingRLE)
+			th.i32(4, parquetEncodingRLE)
+			th.endStruct()
+			header := th.bytes()
+
+			chunks[i] = parquetChunk{
+				offset:       cw.n,
+				values:       int64(end - start),
+				uncompressed: int64(len(header) + len(page)),
+				compressed:   int64(len(header) + len(data)),
+			}
+			if _, err := cw.Write(header); err != nil {
+				return err
+			}
+			if _, err := cw.Write(data); err != nil {
+				return err
+			}
+		}
+		groups = append(groups, chunks)
+		groupRows = append(groupRows, end-start)
+	}
+
+	meta := dt.parquetMetadata(fields, groups, groupRows, codec)
+	meta = binary.LittleEndian.AppendUint32(meta, uint32(len(meta)))
+	meta = append(meta, parquetMagic...)
+	_, err = cw.Write(meta)
+	return err
+}
+
+// parquetPage - returns the uncompressed data page of a column for a range of rows: the definition levels
+// of optional columns followed by the plain encoded non-null values
+func (dt *DataTable) parquetPage(f columnarField, start, end int) ([]byte, error) {
+	var levels []byte
+	var values []byte
+	var boolBits []bool
+
+	for r := start; r < end; r++ {
+		v := cellValue(&dt.Rows[r], f.index)
+		if v == nil {
+			if !f.nullable {
+				return nil, fmt.Errorf("row %d: column %s does not allow NULL", r, f.name)
+			}
+			levels = append(levels, 0)
+			continue
+		}
+		levels = append(levels, 1)
+
+		var err error
+		switch f.kind {
+		case columnarInt64:
+			var x int64
+			x, err = columnarInt64Value(v)
+			values = binary.LittleEndian.AppendUint64(values, uint64(x))
+		case columnarFloat64:
+			var x float64
+			x, err = columnarFloat64Value(v)
+			values = binary.LittleEndian.AppendUint64(values, math.Float64bits(x))
+		case columnarTime:
+			var t time.Time
+			t, err = columnarTimeValue(v)
+			values = binary.LittleEndian.AppendUint64(values, uint64(t.UnixMicro()))
+		case columnarBool:
+			var b bool
+			b, err = columnarBoolValue(v)
+			boolBits = append(boolBits, b)
+		case columnarDecimal:
+			u, derr := decimalUnscaled(v, f.precision, f.scale)
+			if err = derr; err == nil {
+				if f.precision <= parquetMaxDecimalInt64Len {
+					values = binary.LittleEndian.AppendUint64(values, uint64(u.Int64()))
+				} else {
+					values = append(values, decimalBytes(u, 16)...)
+				}
+			}
+		case columnarBinary:
+			b := columnarBytesValue(v)
+			values = binary.LittleEndian.AppendUint32(values, uint32(len(b)))
+			values = append(values, b...)
+		default:
+			s := columnarStringValue(v)
+			values = binary.LittleEndian.AppendUint32(values, uint32(len(s)))
+			values = append(values, s...)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d, column %s: %w", r, f.name, err)
+		}
+	}
+
+	if f.kind == columnarBool {
+		values = make([]byte, (len(boolBits)+7)/8)
+		for i, b := range boolBits {
+			if b {
+				values[i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+
+	if !f.nullable {
+		return values, nil
+	}
+	enc := rleEncode(levels)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(enc)))
+	page = append(page, enc...)
+	return append(page, values...), nil
+}
+
+// rleEncode - encodes levels of bit width 1 as runs of the RLE and bit packing hybrid encoding
+func rleEncode(levels []byte) []byte {
+	var out []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		out = append(out, levels[i])
+		i = j
+	}
+	return out
+}
+
+// parquetPhysical - returns the physical type of a field
+func parquetPhysical(f columnarField) int32 {
+	switch f.kind {
+	case columnarInt64, columnarTime:
+		return parquetInt64
+	case columnarFloat64:
+		return parquetDouble
+	case columnarBool:
+		return parquetBoolean
+	case columnarDecimal:
+		if f.precision <= parquetMaxDecimalInt64Len {
+			return parquetInt64
+		}
+		return parquetFixedLen
+	}
+	return parquetByteArray
+}
+
+// parquetMetadata - returns the FileMetaData of the file
+func (dt *DataTable) parquetMetadata(fields []columnarField, groups [][]parquetChunk, groupRows []int, codec int32) []byte {
+	th := newThriftWriter()
+	th.i32(1, 1)
+
+	th.list(2, thriftStruct, len(fields)+1)
+	th.listStruct()
+	th.string(4, "schema")
+	th.i32(5, int32(len(fields)))
+	th.endStruct()
+	for _, f := range fields {
+		th.listStruct()
+		physical := parquetPhysical(f)
+		th.i32(1, physical)
+		if physical == parquetFixedLen {
+			th.i32(2, 16)
+		}
+		if f.nullable {
+			th.i32(3, parquetOptional)
+		} else {
+			th.i32(3, parquetRequired)
+		}
+		th.string(4, f.name)
+
+		switch f.kind {
+		case columnarString:
+			th.i32(6, parquetConvertedUTF8)
+			th.beginStruct(10)
+			th.beginStruct(parquetLogicalString)
+			th.endStruct()
+			th.endStruct()
+		case columnarTime:
+			// Timestamp adjusted to UTC in microseconds
+			th.i32(6, parquetConvertedMicros)
+			th.beginStruct(10)
+			th.beginStruct(parquetLogicalTimestamp)
+			th.bool(1, true)
+			th.beginStruct(2)
+			th.beginStruct(2)
+			th.endStruct()
+			th.endStruct()
+			th.endStruct()
+			th.endStruct()
+		case columnarDecimal:
+			th.i32(6, parquetConvertedDecimal)
+			th.i32(7, int32(f.scale))
+			th.i32(8, int32(f.precision))
+			th.beginStruct(10)
+			th.beginStruct(parquetLogicalDecimal)
+			th.i32(1, int32(f.scale))
+			th.i32(2, int32(f.precision))
+			th.endStruct()
+			th.endStruct()
+		}
+		th.endStruct()
+	}
+
+	var total int64
+	for _, n := range groupRows {
+		total += int64(n)
+	}
+	th.i64(3, total)
+
+	th.list(4, thriftStruct, len(groups))
+	for g, chunks := range groups {
+		var uncompressed, compressed int64
+		th.listStruct()
+		th.list(1, thriftStruct, len(chunks))
+		for i, c := range chunks {
+			uncompressed += c.uncompressed
+			compressed += c.compressed
+
+			th.listStruct()
+			th.i64(2, c.offset)
+			th.beginStruct(3)
+			th.i32(1, parquetPhysical(fields[i]))
+			th.list(2, thriftI32, 2)
+			th.listI32(parquetEncodingPlain)
+			th.listI32(parquetEncodingRLE)
+			th.list(3, thriftBinary, 1)
+			th.listString(fields[i].name)
+			th.i32(4, codec)
+			th.i64(5, c.values)
+			th.i64(6, c.uncompressed)
+			th.i64(7, c.compressed)
+			th.i64(9, c.offset)
+			th.endStruct()
+			th.endStruct()
+		}
+		th.i64(2, uncompressed)
+		th.i64(3, int64(groupRows[g]))
+		th.i64(5, chunks[0].offset)
+		th.i64(6, compressed)
+		th.field(7, thriftI16)
+		th.buf = binary.AppendVarint(th.buf, int64(g))
+		th.endStruct()
+	}
+
+	th.list(5, thriftStruct, 1)
+	th.listStruct()
+	th.string(1, tableNameKey)
+	th.string(2, dt.Name)
+	th.endStruct()
+	th.string(6, "github.com/eaglebush/datatable")
+	return th.bytes()
+}
+
+// parquetColumn - a leaf column of a Parquet schema as read
+type parquetColumn struct {
+	columnarField
+	physical   int64
+	typeLength int
+	timeUnit   int64 // 1 for milliseconds, 2 for microseconds and 3 for nanoseconds
+	date       bool
+	optional   bool
+}
+
+// ReadParquet - reads a table from a Parquet file with a flat schema. Plain and dictionary encoded pages
+// of version 1 or 2 are supported, uncompressed or compressed with Snappy or gzip. Integers are read as
+// int64, floats and decimals as float64, dates and timestamps as UTC times, strings as string and other
+// byte arrays as []byte
+func ReadParquet(r io.Reader) (dt *DataTable, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 12 || string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		return nil, errors.New("not a parquet file")
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			dt, err = nil, fmt.Errorf("invalid parquet data: %v", rec)
+		}
+	}()
+
+	metaLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta, _, err := readThriftStruct(data[len(data)-8-metaLen : len(data)-8])
+	if err != nil {
+		return nil, err
+	}
+
+	elems := meta.list(2)
+	if len(elems) == 0 {
+		return nil, errors.New("the parquet file has no schema")
+	}
+	if int(elems[0].(thriftFields).int(5, 0)) != len(elems)-1 {
+		return nil, errors.New("nested parquet schemas are not supported")
+	}
+
+	cols := make([]parquetColumn, len(elems)-1)
+	tcols := make([]Column, len(cols))
+	for i, e := range elems[1:] {
+		se := e.(thriftFields)
+		c := parquetColumn{physical: se.int(1, -1), typeLength: int(se.int(2, 0))}
+		c.name = se.string(4)
+		c.index = i
+		switch se.int(3, parquetRequired) {
+		case parquetOptional:
+			c.optional, c.nullable = true, true
+		case parquetRequired:
+		default:
+			return nil, fmt.Errorf("column %s is repeated", c.name)
+		}
+		if se.int(5, 0) > 0 {
+			return nil, errors.New("nested parquet schemas are not supported")
+		}
+
+		logical := se.fields(10)
+		converted := se.int(6, -1)
+		decimal := converted == parquetConvertedDecimal || logical.has(parquetLogicalDecimal)
+		if decimal {
+			c.precision, c.scale = int(se.int(8, 0)), int(se.int(7, 0))
+			if d := logical.fields(parquetLogicalDecimal); d != nil {
+				c.precision, c.scale = int(d.int(2, 0)), int(d.int(1, 0))
+			}
+		}
+
+		switch c.physical {
+		case parquetBoolean:
+			c.kind = columnarBool
+		case parquetInt32, parquetInt64:
+			switch {
+			case decimal:
+				c.kind = columnarDecimal
+			case converted == parquetConvertedDate || logical.has(parquetLogicalDate):
+				c.kind, c.date = columnarTime, true
+			case converted == parquetConvertedMillis:
+				c.kind, c.timeUnit = columnarTime, 1
+			case converted == parquetConvertedMicros:
+				c.kind, c.timeUnit = columnarTime, 2
+			case logical.has(parquetLogicalTimestamp):
+				c.kind, c.timeUnit = columnarTime, 2
+				for unit := range logical.fields(parquetLogicalTimestamp).fields(2) {
+					c.timeUnit = int64(unit)
+				}
+			default:
+				c.kind = columnarInt64
+			}
+		case parquetInt96:
+			c.kind = columnarTime
+		case parquetFloat, parquetDouble:
+			c.kind = columnarFloat64
+		case parquetByteArray, parquetFixedLen:
+			switch {
+			case decimal:
+				c.kind = columnarDecimal
+			case c.physical == parquetByteArray && (converted == parquetConvertedUTF8 || converted == parquetConvertedEnum ||
+				converted == parquetConvertedJSON || logical.has(parquetLogicalString) || logical.has(parquetLogicalEnum) ||
+				logical.has(parquetLogicalJSON)):
+				c.kind = columnarString
+			default:
+				c.kind = columnarBinary
+			}
+		default:
+			return nil, fmt.Errorf("column %s has unknown physical type %d", c.name, c.physical)
+		}
+		cols[i] = c
+		tcols[i] = c.column()
+	}
+
+	dt = NewDataTable("")
+	for _, kv := range meta.list(5) {
+		if f := kv.(thriftFields); f.string(1) == tableNameKey {
+			dt.Name = f.string(2)
+		}
+	}
+	dt.AddColumns(tcols)
+
+	for _, g := range meta.list(4) {
+		rg := g.(thriftFields)
+		rows := int(rg.int(3, 0))
+		chunks := rg.list(1)
+		if len(chunks) != len(cols) {
+			return nil, fmt.Errorf("row group has %d columns, expected %d", len(chunks), len(cols))
+		}
+
+		first := len(dt.Rows)
+		for i := 0; i < rows; i++ {
+			row := dt.NewRow()
+			dt.AddRow(&row)
+		}
+
+		for i, ch := range chunks {
+			cm := ch.(thriftFields).fields(3)
+			if cm == nil {
+				return nil, fmt.Errorf("column %s has no chunk metadata", cols[i].name)
+			}
+			vals, err := readParquetChunk(data, cols[i], cm)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", cols[i].name, err)
+			}
+			for r := 0; r < rows && r < len(vals); r++ {
+				dt.Rows[first+r].Cells[i].Value = vals[r]
+			}
+		}
+	}
+	return dt, nil
+}
+
+// readParquetChunk - returns the values of a column chunk with nil for NULL
+func readParquetChunk(data []byte, c parquetColumn, cm thriftFields) ([]interface{}, error) {
+	codec := cm.int(4, parquetCodecUncompressed)
+	total := int(cm.int(5, 0))
+	pos := int(cm.int(9, 0))
+	if cm.has(11) {
+		pos = int(cm.int(11, 0))
+	}
+
+	var dict []interface{}
+	out := make([]interface{}, 0, total)
+	for len(out) < total {
+		hdr, n, err := readThriftStruct(data[pos:])
+		if err != nil {
+			return nil, err
+		}
+		pos += n
+		size := int(hdr.int(3, 0))
+		usize := int(hdr.int(2, 0))
+		page := data[pos : pos+size]
+		pos += size
+
+		switch hdr.int(1, -1) {
+		case parquetPageDictionary:
+			raw, err := parquetDecompress(codec, page, usize)
+			if err != nil {
+				return nil, err
+			}
+			if dict, _, err = parquetPlain(c, raw, int(hdr.fields(7).int(1, 0))); err != nil {
+				return nil, err
+			}
+		case parquetPageData:
+			dh := hdr.fields(5)
+			raw, err := parquetDecompress(codec, page, usize)
+			if err != nil {
+				return nil, err
+			}
+			count := int(dh.int(1, 0))
+			var defs []int
+			if c.optional {
+				l := int(binary.LittleEndian.Uint32(raw))
+				if defs, err = rleDecode(raw[4:4+l], 1, count); err != nil {
+					return nil, err
+				}
+				raw = raw[4+l:]
+			}
+			vals, err := parquetValues(c, raw, dh.int(2, parquetEncodingPlain), count, defs, dict)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, vals...)
+		case parquetPageDataV2:
+			dh := hdr.fields(8)
+			count := int(dh.int(1, 0))
+			defLen, repLen := int(dh.int(5, 0)), int(dh.int(6, 0))
+			raw := page[repLen+defLen:]
+			if dh.bool(7, true) {
+				if raw, err = parquetDecompress(codec, raw, usize-repLen-defLen); err != nil {
+					return nil, err
+				}
+			}
+			var defs []int
+			if c.optional {
+				if defs, err = rleDecode(page[repLen:repLen+defLen], 1, count); err != nil {
+					return nil, err
+				}
+			}
+			vals, err := parquetValues(c, raw, dh.int(4, parquetEncodingPlain), count, defs, dict)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, vals...)
+		}
+	}
+	return out, nil
+}
+
+// parquetValues - decodes the values of a data page and places them at the defined levels
+func parquetValues(c parquetColumn, raw []byte, encoding int64, count int, defs []int, dict []interface{}) ([]interface{}, error) {
+	present := count
+	if defs != nil {
+		present = 0
+		for _, d := range defs {
+			present += d
+		}
+	}
+
+	var vals []interface{}
+	switch encoding {
+	case parquetEncodingPlain:
+		var err error
+		if vals, _, err = parquetPlain(c, raw, present); err != nil {
+			return nil, err
+		}
+	case parquetEncodingPlainDict, parquetEncodingRLEDict:
+		if dict == nil {
+			return nil, errors.New("dictionary encoded page without a dictionary")
+		}
+		idx, err := rleDecode(raw[1:], int(raw[0]), present)
+		if err != nil {
+			return nil, err
+		}
+		vals = make([]interface{}, present)
+		for i, x := range idx {
+			if x < 0 || x >= len(dict) {
+				return nil, fmt.Errorf("dictionary index %d out of range", x)
+			}
+			vals[i] = dict[x]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding %d", encoding)
+	}
+
+	if defs == nil {
+		return vals, nil
+	}
+	out := make([]interface{}, count)
+	j := 0
+	for i, d := range defs {
+		if d == 1 {
+			out[i] = vals[j]
+			j++
+		}
+	}
+	return out, nil
+}
+
+// parquetPlain - decodes count plain encoded values and returns them with the number of bytes read
+func parquetPlain(c parquetColumn, raw []byte, count int) ([]interface{}, int, error) {
+	vals := make([]interface{}, count)
+	pos := 0
+	for i := 0; i < count; i++ {
+		switch c.physical {
+		case parquetBoolean:
+			vals[i] = raw[i/8]&(1<<(i%8)) != 0
+			pos = (i + 8) / 8
+			continue
+		case parquetInt32:
+			x := int64(int32(binary.LittleEndian.Uint32(raw[pos:])))
+			pos += 4
+			switch {
+			case c.kind == columnarDecimal:
+				vals[i] = decimalFloat(big.NewInt(x), c.scale)
+			case c.date:
+				vals[i] = time.Unix(x*86400, 0).UTC()
+			default:
+				vals[i] = x
+			}
+		case parquetInt64:
+			x := int64(binary.LittleEndian.Uint64(raw[pos:]))
+			pos += 8
+			switch {
+			case c.kind == columnarDecimal:
+				vals[i] = decimalFloat(big.NewInt(x), c.scale)
+			case c.kind == columnarTime:
+				switch c.timeUnit {
+				case 1:
+					vals[i] = time.UnixMilli(x).UTC()
+				case 3:
+					vals[i] = time.Unix(0, x).UTC()
+				default:
+					vals[i] = time.UnixMicro(x).UTC()
+				}
+			default:
+				vals[i] = x
+			}
+		case parquetInt96:
+			nanos := int64(binary.LittleEndian.Uint64(raw[pos:]))
+			day := int64(binary.LittleEndian.Uint32(raw[pos+8:]))
+			pos += 12
+			vals[i] = time.Unix((day-2440588)*86400, nanos).UTC()
+		case parquetFloat:
+			vals[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[pos:])))
+			pos += 4
+		case parquetDouble:
+			vals[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[pos:]))
+			pos += 8
+		case parquetByteArray, parquetFixedLen:
+			l := c.typeLength
+			if c.physical == parquetByteArray {
+				l = int(binary.LittleEndian.Uint32(raw[pos:]))
+				pos += 4
+			}
+			b := raw[pos : pos+l]
+			pos += l
+			switch c.kind {
+			case columnarDecimal:
+				vals[i] = decimalFloat(decimalFromBytes(b), c.scale)
+			case columnarString:
+				vals[i] = string(b)
+			default:
+				vals[i] = bytes.Clone(b)
+			}
+		}
+	}
+	return vals, pos, nil
+}
+
+// rleDecode - decodes count values of the RLE and bit packing hybrid encoding
+func rleDecode(data []byte, bitWidth, count int) ([]int, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, fmt.Errorf("invalid bit width %d", bitWidth)
+	}
+
+	out := make([]int, 0, count)
+	byteWidth := (bitWidth + 7) / 8
+	pos := 0
+	for len(out) < count {
+		h, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return nil, errors.New("invalid run header")
+		}
+		pos += n
+
+		if h&1 == 0 {
+			// A run of one repeated value
+			run := int(h >> 1)
+			v := 0
+			for i := 0; i < byteWidth; i++ {
+				v |= int(data[pos+i]) << (8 * i)
+			}
+			pos += byteWidth
+			for i := 0; i < run && len(out) < count; i++ {
+				out = append(out, v)
+			}
+			continue
+		}
+
+		// Groups of eight bit packed values, least significant bit first
+		values := int(h>>1) * 8
+		var acc uint64
+		var have int
+		mask := uint64(1)<<bitWidth - 1
+		for i := 0; i < values; i++ {
+			for have < bitWidth {
+				acc |= uint64(data[pos]) << have
+				pos++
+				have += 8
+			}
+			if len(out) < count {
+				out = append(out, int(acc&mask))
+			}
+			acc >>= bitWidth
+			have -= bitWidth
+		}
+	}
+	return out, nil
+}
+
+// parquetDecompress - decompresses page data
+func parquetDecompress(codec int64, data []byte, size int) ([]byte, error) {
+	switch codec {
+	case parquetCodecUncompressed:
+		return data, nil
+	case parquetCodecSnappy:
+		return snappyDecode(data)
+	case parquetCodecGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, 0, size)
+		buf := bytes.NewBuffer(out)
+		if _, err := io.Copy(buf, zr); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported compression codec %d", codec)
+}
+
+// snappyDecode - decodes a block in the Snappy format
+func snappyDecode(src []byte) ([]byte, error) {
+	n, l := binary.Uvarint(src)
+	if l <= 0 || n > uint64(math.MaxInt32) {
+		return nil, errors.New("invalid snappy block")
+	}
+
+	dst := make([]byte, 0, n)
+	for s := l; s < len(src); {
+		tag := src[s]
+		var length, offset int
+		switch tag & 3 {
+		case 0:
+			length = int(tag >> 2)
+			s++
+			if length >= 60 {
+				nb := length - 59
+				length = 0
+				for i := 0; i < nb; i++ {
+					length |= int(src[s+i]) << (8 * i)
+				}
+				s += nb
+			}
+			length++
+			if length > len(src)-s {
+				return nil, errors.New("invalid snappy literal")
+			}
+			dst = append(dst, src[s:s+length]...)
+			s += length
+			continue
+		case 1:
+			length = 4 + int(tag>>2&7)
+			offset = int(tag&0xe0)<<3 | int(src[s+1])
+			s += 2
+		case 2:
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[s+1:]))
+			s += 3
+		case 3:
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[s+1:]))
+			s += 5
+		}
+		if offset <= 0 || offset > len(dst) {
+			return nil, errors.New("invalid snappy copy offset")
+		}
+		for i := 0; i < length; i++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+	if len(dst) != int(n) {
+		return nil, errors.New("snappy length mismatch")
+	}
+	return dst, nil
+}
@@ -0,0 +1,236 @@
+package datatable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParquetRoundTrip(t *testing.T) {
+	for _, opts := range []ParquetOptions{{}, {RowGroupSize: 2, Compression: ParquetGzip}} {
+		var buf bytes.Buffer
+		if err := newColumnarTestTable().WriteParquet(&buf, opts); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadParquet(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkColumnarTable(t, got)
+	}
+}
+
+func TestParquetErrors(t *testing.T) {
+	dt := newColumnarTestTable()
+	dt.Rows[3].Cells[0].Value = nil
+	if err := dt.WriteParquet(&bytes.Buffer{}, ParquetOptions{}); err == nil || !strings.Contains(err.Error(), "ID") {
+		t.Errorf("expected a NULL error, got %v", err)
+	}
+
+	if _, err := ReadParquet(strings.NewReader("PAR1")); err == nil {
+		t.Error("expected an error for a short file")
+	}
+	if _, err := ReadParquet(strings.NewReader("PAR1\x05\x00\x00\x00garbagePAR1")); err == nil {
+		t.Error("expected an error for invalid metadata")
+	}
+}
+
+func TestReadParquetGolden(t *testing.T) {
+	for _, name := range []string{"arrowgo_v1_snappy_dict.parquet", "arrowgo_v2_gzip_plain.parquet"} {
+		f, err := os.Open("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadParquet(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		checkArrowGoTable(t, got)
+	}
+}
+
+// TestWriteParquetGolden - compares the output of WriteParquet with testdata/orders.parquet, which arrow-go
+// v18.8.0 reads back as newColumnarTestTable, and checks the footer and the start of the schema byte by byte
+func TestWriteParquetGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newColumnarTestTable().WriteParquet(&buf, ParquetOptions{RowGroupSize: 3}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	golden, err := os.ReadFile("testdata/orders.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, golden) {
+		t.Error("the output differs from testdata/orders.parquet")
+	}
+
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatal("missing parquet magic")
+	}
+	mlen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if mlen <= 0 || mlen > len(data)-12 {
+		t.Fatalf("invalid metadata length %d", mlen)
+	}
+	meta := data[len(data)-8-mlen : len(data)-8]
+
+	// FileMetaData in the thrift compact protocol: version 1, then a list of 8 schema elements, the root
+	// named schema with 7 children, ID as a required INT64 and Customer as an optional BYTE_ARRAY with the
+	// UTF8 converted type
+	want := []byte{0x15, 0x02, 0x19, 0x8c}
+	want = append(append(want, 0x48, 0x06), "schema"...)
+	want = append(want, 0x15, 0x0e, 0x00)
+	want = append(append(want, 0x15, 0x04, 0x25, 0x00, 0x18, 0x02), "ID"...)
+	want = append(want, 0x00)
+	want = append(append(want, 0x15, 0x0c, 0x25, 0x02, 0x18, 0x08), "Customer"...)
+	want = append(want, 0x25, 0x00)
+	if !bytes.HasPrefix(meta, want) {
+		t.Errorf("unexpected metadata start\n% x\nexpected\n% x", meta[:len(want)], want)
+	}
+
+	// The key value metadata with the table name, then created_by, end the metadata
+	end := []byte("\x18\x0edatatable.name\x18\x06Orders\x00\x18\x1egithub.com/eaglebush/datatable\x00")
+	if !bytes.HasSuffix(meta, end) {
+		t.Errorf("unexpected metadata end % x", meta[len(meta)-len(end):])
+	}
+}
+
+// TestReadParquetDictionary - reads a file with a snappy compressed dictionary page and a version 2 data page
+func TestReadParquetDictionary(t *testing.T) {
+	// Dictionary of "red" and "green", plain encoded
+	dict := []byte{3, 0, 0, 0, 'r', 'e', 'd', 5, 0, 0, 0, 'g', 'r', 'e', 'e', 'n'}
+	// Snappy block: length, a literal of the first 7 bytes, then the rest as a literal
+	snappyDict := append([]byte{byte(len(dict)), 6 << 2}, dict[:7]...)
+	snappyDict = append(snappyDict, byte(len(dict)-7-1)<<2)
+	snappyDict = append(snappyDict, dict[7:]...)
+
+	// Five rows with the fourth NULL: definition levels 1,1,1,0,1 as one bit packed group
+	defs := []byte{3, 0x17}
+	// Indices 0,1,1,0 with a bit width of 1 as an RLE run of 0, a run of two 1s and a run of 0
+	values := []byte{1, 2, 0, 4, 1, 2, 0}
+	snappyValues := append([]byte{byte(len(values)), byte(len(values)-1) << 2}, values...)
+
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	dictOffset := file.Len()
+	th := newThriftWriter()
+	th.i32(1, parquetPageDictionary)
+	th.i32(2, int32(len(dict)))
+	th.i32(3, int32(len(snappyDict)))
+	th.beginStruct(7)
+	th.i32(1, 2)
+	th.i32(2, parquetEncodingPlain)
+	th.endStruct()
+	file.Write(th.bytes())
+	file.Write(snappyDict)
+
+	dataOffset := file.Len()
+	th = newThriftWriter()
+	th.i32(1, parquetPageDataV2)
+	th.i32(2, int32(len(defs)+len(values)))
+	th.i32(3, int32(len(defs)+len(snappyValues)))
+	th.beginStruct(8)
+	th.i32(1, 5)
+	th.i32(2, 1)
+	th.i32(3, 5)
+	th.i32(4, parquetEncodingRLEDict)
+	th.i32(5, int32(len(defs)))
+	th.i32(6, 0)
+	th.endStruct()
+	file.Write(th.bytes())
+	file.Write(defs)
+	file.Write(snappyValues)
+
+	th = newThriftWriter()
+	th.i32(1, 1)
+	th.list(2, thriftStruct, 2)
+	th.listStruct()
+	th.string(4, "schema")
+	th.i32(5, 1)
+	th.endStruct()
+	th.listStruct()
+	th.i32(1, parquetByteArray)
+	th.i32(3, parquetOptional)
+	th.string(4, "Color")
+	th.i32(6, parquetConvertedUTF8)
+	th.endStruct()
+	th.i64(3, 5)
+	th.list(4, thriftStruct, 1)
+	th.listStruct()
+	th.list(1, thriftStruct, 1)
+	th.listStruct()
+	th.i64(2, int64(dictOffset))
+	th.beginStruct(3)
+	th.i32(1, parquetByteArray)
+	th.list(2, thriftI32, 1)
+	th.listI32(parquetEncodingRLEDict)
+	th.list(3, thriftBinary, 1)
+	th.listString("Color")
+	th.i32(4, parquetCodecSnappy)
+	th.i64(5, 5)
+	th.i64(6, 0)
+	th.i64(7, 0)
+	th.i64(9, int64(dataOffset))
+	th.i64(11, int64(dictOffset))
+	th.endStruct()
+	th.endStruct()
+	th.i64(2, 0)
+	th.i64(3, 5)
+	th.endStruct()
+	meta := th.bytes()
+	file.Write(meta)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(meta))))
+	file.WriteString(parquetMagic)
+
+	got, err := ReadParquet(&file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Columns) != 1 || got.Columns[0].Name != "Color" || got.Columns[0].Type != reflect.TypeOf("") {
+		t.Fatalf("unexpected columns %+v", got.Columns)
+	}
+	want := []interface{}{"red", "green", "green", nil, "red"}
+	if len(got.Rows) != len(want) {
+		t.Fatalf("got %d rows", len(got.Rows))
+	}
+	for i, v := range want {
+		if g := got.Rows[i].Cells[0].Value; g != v {
+			t.Errorf("row %d: got %v, expected %v", i, g, v)
+		}
+	}
+}
+
+func TestRLEDecode(t *testing.T) {
+	// A run of three 5s with a bit width of 3, then one bit packed group of 0..7
+	data := []byte{3 << 1, 5, 1<<1 | 1, 0x88, 0xc6, 0xfa}
+	got, err := rleDecode(data, 3, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{5, 5, 5, 0, 1, 2, 3, 4, 5, 6, 7}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+}
+
+func TestSnappyDecode(t *testing.T) {
+	// "abcabcabcd": a literal "abc", a copy of 6 bytes at offset 3 and a literal "d"
+	src := []byte{10, 2 << 2, 'a', 'b', 'c', (6-4)<<2 | 1, 3, 0 << 2, 'd'}
+	got, err := snappyDecode(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "abcabcabcd" {
+		t.Errorf("got %q", got)
+	}
+
+	if _, err := snappyDecode([]byte{4, 0 << 2, 'a', 1<<2 | 1, 9}); err == nil {
+		t.Error("expected an offset error")
+	}
+}
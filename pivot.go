@@ -0,0 +1,164 @@
+package datatable
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Pivot - returns a new table with one row per distinct combination of the row key columns and one column
+// per distinct value of pivotCol, named after the value in the order the values first appear. Each cell
+// holds the aggregation of valueCol over the rows sharing the row keys and pivot value, or null if there
+// are no such rows (0 for AggCount). Returns nil if a column does not exist
+func (dt *DataTable) Pivot(rowKeys []string, pivotCol string, valueCol string, agg Aggregation) *DataTable {
+	keyIdx := make([]int, len(rowKeys))
+	for i, k := range rowKeys {
+		if keyIdx[i] = dt.columnIndex(k); keyIdx[i] == -1 {
+			return nil
+		}
+	}
+	pidx := dt.columnIndex(pivotCol)
+	vidx := dt.columnIndex(valueCol)
+	if pidx == -1 || vidx == -1 {
+		return nil
+	}
+
+	res := NewDataTable(dt.Name)
+	for _, i := range keyIdx {
+		col := dt.Columns[i]
+		col.PrimaryKey = false
+		res.AddColumns([]Column{col})
+	}
+
+	vcol := dt.Columns[vidx]
+	vtype := aggregateType(agg, vcol.Type)
+
+	// Distinct pivot values become columns and distinct row keys become rows
+	pivots := make(map[string]int)
+	groups := make(map[string]int)
+	var cells [][]*aggregator
+	var keys [][]interface{}
+
+	for i := range dt.Rows {
+		r := &dt.Rows[i]
+
+		pname := pivotColumnName(r.Value(pidx))
+		pk := strings.ToLower(pname)
+		pc, ok := pivots[pk]
+		if !ok {
+			pc = len(pivots)
+			pivots[pk] = pc
+			for res.columnIndex(pname) != -1 {
+				pname += "_"
+			}
+			res.AddColumns([]Column{{Name: pname, Type: vtype, DBType: vcol.DBType, Length: vcol.Length}})
+		}
+
+		kv := make([]interface{}, len(keyIdx))
+		for j, c := range keyIdx {
+			kv[j] = cellValue(r, c)
+		}
+		gk := keyString(kv...)
+		g, ok := groups[gk]
+		if !ok {
+			g = len(keys)
+			groups[gk] = g
+			keys = append(keys, kv)
+			cells = append(cells, nil)
+		}
+
+		for len(cells[g]) <= pc {
+			cells[g] = append(cells[g], nil)
+		}
+		if cells[g][pc] == nil {
+			cells[g][pc] = newAggregator(agg)
+		}
+		cells[g][pc].add(cellValue(r, vidx))
+	}
+
+	for g, kv := range keys {
+		nr := res.NewRow()
+		for j, v := range kv {
+			nr.Cells[j].Value = copyValue(v)
+		}
+		if agg == AggCount {
+			for j := len(keyIdx); j < len(nr.Cells); j++ {
+				nr.Cells[j].Value = int64(0)
+			}
+		}
+		for pc, a := range cells[g] {
+			if a != nil {
+				nr.Cells[len(keyIdx)+pc].Value = a.resultAs(vtype)
+			}
+		}
+		res.AddRow(&nr)
+	}
+
+	return res
+}
+
+// Unpivot - returns a new table that turns the value columns into rows. Each row has the id columns,
+// a nameCol column with the name of the value column and a valueCol column with its value.
+// Null values are skipped. Returns nil if a column does not exist
+func (dt *DataTable) Unpivot(idCols []string, valueCols []string, nameCol, valueCol string) *DataTable {
+	idIdx := make([]int, len(idCols))
+	for i, c := range idCols {
+		if idIdx[i] = dt.columnIndex(c); idIdx[i] == -1 {
+			return nil
+		}
+	}
+
+	valIdx := make([]int, len(valueCols))
+	var vtype reflect.Type
+	for i, c := range valueCols {
+		if valIdx[i] = dt.columnIndex(c); valIdx[i] == -1 {
+			return nil
+		}
+		t := dt.Columns[valIdx[i]].Type
+		switch {
+		case i == 0:
+			vtype = t
+		case vtype != t:
+			vtype = reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+	}
+
+	res := NewDataTable(dt.Name)
+	for _, i := range idIdx {
+		col := dt.Columns[i]
+		col.PrimaryKey = false
+		res.AddColumns([]Column{col})
+	}
+	res.AddColumns([]Column{
+		{Name: nameCol, Type: reflect.TypeOf("")},
+		{Name: valueCol, Type: vtype},
+	})
+
+	for i := range dt.Rows {
+		r := &dt.Rows[i]
+		for _, vi := range valIdx {
+			v := cellValue(r, vi)
+			if v == nil {
+				continue
+			}
+
+			nr := res.NewRow()
+			for j, c := range idIdx {
+				nr.Cells[j].Value = copyValue(cellValue(r, c))
+			}
+			nr.Cells[len(idIdx)].Value = dt.Columns[vi].Name
+			nr.Cells[len(idIdx)+1].Value = copyValue(v)
+			res.AddRow(&nr)
+		}
+	}
+
+	return res
+}
+
+// pivotColumnName - the column name for a pivot value
+func pivotColumnName(value interface{}) string {
+	if value == nil {
+		return "NULL"
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,127 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPivot(t *testing.T) {
+	dt := NewDataTable("Sales")
+	dt.AddColumns([]Column{
+		{Name: "Region", Type: reflect.TypeOf("")},
+		{Name: "Month", Type: reflect.TypeOf("")},
+		{Name: "Amount", Type: reflect.TypeOf(0)},
+	})
+	for _, v := range []struct {
+		region, month string
+		amount        int
+	}{
+		{"North", "Jan", 10}, {"North", "Feb", 20}, {"South", "Jan", 5},
+		{"North", "Jan", 7}, {"South", "Mar", 1}, {"East", "Feb", 3},
+	} {
+		r := dt.NewRow()
+		r.Cells[0].Value = v.region
+		r.Cells[1].Value = v.month
+		r.Cells[2].Value = v.amount
+		dt.AddRow(&r)
+	}
+
+	p := dt.Pivot([]string{"Region"}, "Month", "Amount", AggSum)
+	if p == nil {
+		t.Fatal("expected a pivot table")
+	}
+
+	var names []string
+	for _, c := range p.Columns {
+		names = append(names, c.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Region", "Jan", "Feb", "Mar"}) {
+		t.Fatalf("unexpected columns %v", names)
+	}
+	if p.RowCount != 3 {
+		t.Fatalf("expected 3 rows, got %d", p.RowCount)
+	}
+
+	north := p.Rows[0]
+	if north.ValueString("Region") != "North" || north.ValueInt64("Jan") != 17 || north.ValueInt64("Feb") != 20 || north.Value("Mar") != nil {
+		t.Fatalf("unexpected North row %v", north.Cells)
+	}
+	if p.Columns[1].Type != reflect.TypeOf(int64(0)) {
+		t.Fatalf("expected int64 sums, got %v", p.Columns[1].Type)
+	}
+
+	if dt.Pivot([]string{"Nope"}, "Month", "Amount", AggSum) != nil {
+		t.Fatal("expected nil for a missing column")
+	}
+}
+
+func TestUnpivot(t *testing.T) {
+	dt := NewDataTable("Sales")
+	dt.AddColumns([]Column{
+		{Name: "Region", Type: reflect.TypeOf("")},
+		{Name: "Month", Type: reflect.TypeOf("")},
+		{Name: "Amount", Type: reflect.TypeOf(0)},
+	})
+	for _, v := range []struct {
+		region, month string
+		amount        int
+	}{
+		{"North", "Jan", 10}, {"North", "Feb", 20}, {"South", "Jan", 5},
+		{"North", "Jan", 7}, {"South", "Mar", 1}, {"East", "Feb", 3},
+	} {
+		r := dt.NewRow()
+		r.Cells[0].Value = v.region
+		r.Cells[1].Value = v.month
+		r.Cells[2].Value = v.amount
+		dt.AddRow(&r)
+	}
+
+	p := dt.Pivot([]string{"Region"}, "Month", "Amount", AggCount)
+	u := p.Unpivot([]string{"Region"}, []string{"Jan", "Feb", "Mar"}, "Month", "Count")
+	if u == nil {
+		t.Fatal("expected an unpivoted table")
+	}
+
+	// Count cells are never null, so every region has a row per month
+	if u.RowCount != 9 || u.ColumnCount != 3 {
+		t.Fatalf("expected 9 rows and 3 columns, got %d and %d", u.RowCount, u.ColumnCount)
+	}
+	r := u.Rows[0]
+	if r.ValueString("Region") != "North" || r.ValueString("Month") != "Jan" || r.ValueInt64("Count") != 2 {
+		t.Fatalf("unexpected first row %v", r.Cells)
+	}
+
+	s := dt.Pivot([]string{"Region"}, "Month", "Amount", AggSum)
+	if n := s.Unpivot([]string{"Region"}, []string{"Jan", "Feb", "Mar"}, "Month", "Amount").RowCount; n != 5 {
+		t.Fatalf("expected null sums to be skipped, got %d rows", n)
+	}
+}
+
+func TestPivotWholeFloats(t *testing.T) {
+	dt := NewDataTable("Sales")
+	dt.AddColumns([]Column{
+		{Name: "Region", Type: reflect.TypeOf("")},
+		{Name: "Amount", Type: reflect.TypeOf(0.0)},
+	})
+	for _, amt := range []float64{1, 2} {
+		r := dt.NewRow()
+		r.Cells[0].Value = "North"
+		r.Cells[1].Value = amt
+		dt.AddRow(&r)
+	}
+
+	p := dt.Pivot([]string{"Region"}, "Region", "Amount", AggSum)
+	if p == nil || p.Rows[0].Value("North") != 3.0 || p.Rows[0].ValueFloat64("North") != 3 {
+		t.Fatalf("expected a float64 sum of 3, got %v", p.Rows[0].Cells)
+	}
+
+	if err := dt.AddWindowColumn("Total", WindowSpec{Func: WindowRunningSum, Column: "Amount"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dt.AddWindowColumn("Avg", WindowSpec{Func: WindowRunningAvg, Column: "Amount"}); err != nil {
+		t.Fatal(err)
+	}
+	if dt.Rows[1].ValueFloat64("Total") != 3 || dt.Rows[1].ValueFloat64("Avg") != 1.5 {
+		t.Fatalf("unexpected running values %v", dt.Rows[1].Cells)
+	}
+}
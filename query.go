@@ -0,0 +1,1484 @@
+package datatable
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryTables - runs a SELECT statement against in-memory tables and returns the result as a new table.
+// Table names in the query are matched to the keys of tables, ignoring case.
+//
+// The supported subset is SELECT [DISTINCT] [TOP n] with expressions and aliases, FROM with
+// INNER, LEFT and CROSS joins, WHERE, GROUP BY, HAVING, ORDER BY with column names, aliases or
+// ordinals, and LIMIT/OFFSET. Expressions support arithmetic, comparison, AND/OR/NOT, IN, BETWEEN,
+// LIKE (case insensitive), IS NULL, CASE, CAST, the aggregates COUNT, SUM, AVG, MIN and MAX and
+// the scalar functions UPPER, LOWER, LENGTH, LEN, TRIM, LTRIM, RTRIM, SUBSTR, SUBSTRING, REPLACE,
+// CONCAT, COALESCE, IFNULL, ISNULL, NULLIF, ABS, ROUND, FLOOR, CEIL, CEILING, YEAR, MONTH and DAY
+func QueryTables(sql string, tables map[string]*DataTable) (*DataTable, error) {
+	return queryTables(sql, tables, nil)
+}
+
+// queryTables - runs a query with values for its ? and $n placeholders
+func queryTables(sql string, tables map[string]*DataTable, args []interface{}) (*DataTable, error) {
+	stmt, err := parseSQL(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &sqlQuery{stmt: stmt, args: args}
+	if err := q.bind(tables); err != nil {
+		return nil, err
+	}
+	return q.run()
+}
+
+// compileFilter - parses a WHERE clause expression over the columns of the table and returns a function
+// that tells if a row matches it. Rows where the expression is NULL do not match
+func (dt *DataTable) compileFilter(expr string) (func(row *Row) (bool, error), error) {
+	toks, err := sqlLex(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &sqlParser{src: expr, toks: toks}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != sqlTokEOF {
+		return nil, p.errorf("unexpected %s", p.peek().text)
+	}
+	if p.nparams > 0 {
+		return nil, errors.New("placeholders are not supported in filters")
+	}
+
+	q := &sqlQuery{stmt: &sqlSelect{where: e}, tables: []*DataTable{dt}, aliases: []string{dt.Name}}
+	if err := q.bindExpr(e, 1, false); err != nil {
+		return nil, err
+	}
+	return func(row *Row) (bool, error) {
+		return q.truth(e, &sqlContext{src: []*Row{row}})
+	}, nil
+}
+
+// sqlQuery - a statement bound to its source tables
+type sqlQuery struct {
+	stmt    *sqlSelect
+	tables  []*DataTable
+	aliases []string
+	aggs    []*sqlFunc
+	grouped bool
+	args    []interface{}
+}
+
+// sqlContext - the rows an expression is evaluated against. In a grouped query src is the first
+// row of the group and aggs holds the results of the aggregates
+type sqlContext struct {
+	src  []*Row
+	aggs map[*sqlFunc]interface{}
+}
+
+var sqlAggregates = map[string]Aggregation{
+	"COUNT": AggCount,
+	"SUM":   AggSum,
+	"AVG":   AggAvg,
+	"MIN":   AggMin,
+	"MAX":   AggMax,
+}
+
+// bind - resolves tables and columns and expands stars
+func (q *sqlQuery) bind(tables map[string]*DataTable) error {
+	s := q.stmt
+
+	for _, ref := range s.from {
+		var dt *DataTable
+		for name, t := range tables {
+			if strings.EqualFold(name, ref.name) {
+				dt = t
+				break
+			}
+		}
+		if dt == nil {
+			return fmt.Errorf("table %s does not exist", ref.name)
+		}
+		for _, a := range q.aliases {
+			if strings.EqualFold(a, ref.alias) {
+				return fmt.Errorf("table alias %s is used more than once", ref.alias)
+			}
+		}
+		q.tables = append(q.tables, dt)
+		q.aliases = append(q.aliases, ref.alias)
+	}
+
+	// Expand stars into column references
+	var items []sqlSelectItem
+	for _, it := range s.items {
+		st, ok := it.expr.(*sqlStar)
+		if !ok {
+			items = append(items, it)
+			continue
+		}
+
+		found := false
+		for ti, dt := range q.tables {
+			if st.table != "" && !strings.EqualFold(st.table, q.aliases[ti]) {
+				continue
+			}
+			found = true
+			for ci, col := range dt.Columns {
+				items = append(items, sqlSelectItem{
+					expr: &sqlColumnRef{table: q.aliases[ti], name: col.Name, tidx: ti, cidx: ci},
+					text: col.Name,
+				})
+			}
+		}
+		if !found {
+			return fmt.Errorf("table %s does not exist in the query", st.table)
+		}
+	}
+	s.items = items
+
+	// Join conditions may only use the tables joined so far
+	for i := range s.from {
+		if s.from[i].on == nil {
+			continue
+		}
+		if err := q.bindExpr(s.from[i].on, i+1, false); err != nil {
+			return err
+		}
+	}
+
+	if s.where != nil {
+		if err := q.bindExpr(s.where, len(q.tables), false); err != nil {
+			return err
+		}
+	}
+
+	for i, e := range s.groupBy {
+		e, err := q.selectItemRef(e, false)
+		if err != nil {
+			return fmt.Errorf("GROUP BY %w", err)
+		}
+		s.groupBy[i] = e
+		if err := q.bindExpr(e, len(q.tables), false); err != nil {
+			return err
+		}
+	}
+
+	for _, it := range s.items {
+		if err := q.bindExpr(it.expr, len(q.tables), true); err != nil {
+			return err
+		}
+	}
+
+	if s.having != nil {
+		s.having = q.substituteAliases(s.having)
+		if err := q.bindExpr(s.having, len(q.tables), true); err != nil {
+			return err
+		}
+	}
+
+	for i := range s.orderBy {
+		e, err := q.selectItemRef(s.orderBy[i].expr, true)
+		if err != nil {
+			return fmt.Errorf("ORDER BY %w", err)
+		}
+		s.orderBy[i].expr = e
+		if err := q.bindExpr(e, len(q.tables), true); err != nil {
+			return err
+		}
+	}
+
+	q.grouped = len(s.groupBy) > 0 || len(q.aggs) > 0
+	if s.having != nil && !q.grouped {
+		return errors.New("HAVING requires GROUP BY or an aggregate")
+	}
+
+	// A grouped query may only use the grouped expressions outside of aggregates
+	if q.grouped {
+		for _, it := range s.items {
+			if err := q.checkGrouped(it.expr); err != nil {
+				return err
+			}
+		}
+		if s.having != nil {
+			if err := q.checkGrouped(s.having); err != nil {
+				return err
+			}
+		}
+		for _, o := range s.orderBy {
+			if err := q.checkGrouped(o.expr); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkGrouped - returns an error if an expression uses a column outside of an aggregate that is not
+// grouped on
+func (q *sqlQuery) checkGrouped(e sqlExpr) error {
+	for _, g := range q.stmt.groupBy {
+		if sqlExprEqual(e, g) {
+			return nil
+		}
+	}
+
+	switch v := e.(type) {
+	case *sqlColumnRef:
+		return fmt.Errorf("column %s must be in GROUP BY or used in an aggregate", v.name)
+	case *sqlFunc:
+		if _, ok := sqlAggregates[v.name]; ok {
+			return nil
+		}
+	}
+
+	for _, c := range sqlChildren(e) {
+		if err := q.checkGrouped(*c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlExprEqual - reports whether two bound expressions are the same. Column references are equal when
+// they refer to the same column
+func sqlExprEqual(a, b sqlExpr) bool {
+	same := false
+	switch x := a.(type) {
+	case *sqlColumnRef:
+		y, ok := b.(*sqlColumnRef)
+		return ok && x.tidx == y.tidx && x.cidx == y.cidx
+	case *sqlLiteral:
+		y, ok := b.(*sqlLiteral)
+		return ok && reflect.DeepEqual(x.value, y.value)
+	case *sqlParam:
+		y, ok := b.(*sqlParam)
+		return ok && x.index == y.index
+	case *sqlUnary:
+		y, ok := b.(*sqlUnary)
+		same = ok && x.op == y.op
+	case *sqlBinary:
+		y, ok := b.(*sqlBinary)
+		same = ok && x.op == y.op
+	case *sqlFunc:
+		y, ok := b.(*sqlFunc)
+		same = ok && x.name == y.name && x.star == y.star && x.distinct == y.distinct
+	case *sqlIn:
+		y, ok := b.(*sqlIn)
+		same = ok && x.not == y.not
+	case *sqlBetween:
+		y, ok := b.(*sqlBetween)
+		same = ok && x.not == y.not
+	case *sqlIsNull:
+		y, ok := b.(*sqlIsNull)
+		same = ok && x.not == y.not
+	case *sqlLike:
+		y, ok := b.(*sqlLike)
+		same = ok && x.not == y.not
+	case *sqlCase:
+		y, ok := b.(*sqlCase)
+		same = ok && (x.operand == nil) == (y.operand == nil) && (x.els == nil) == (y.els == nil) && len(x.whens) == len(y.whens)
+	case *sqlCast:
+		y, ok := b.(*sqlCast)
+		same = ok && x.toType == y.toType
+	}
+	if !same {
+		return false
+	}
+
+	ac, bc := sqlChildren(a), sqlChildren(b)
+	if len(ac) != len(bc) {
+		return false
+	}
+	for i := range ac {
+		if !sqlExprEqual(*ac[i], *bc[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectItemRef - replaces an ordinal or a select alias with the select item expression. Returns an error for
+// an ordinal without a select item
+func (q *sqlQuery) selectItemRef(e sqlExpr, aliases bool) (sqlExpr, error) {
+	switch v := e.(type) {
+	case *sqlLiteral:
+		if n, ok := v.value.(int64); ok {
+			if n < 1 || n > int64(len(q.stmt.items)) {
+				return nil, fmt.Errorf("position %d is out of range", n)
+			}
+			return q.stmt.items[n-1].expr, nil
+		}
+	case *sqlColumnRef:
+		if aliases && v.table == "" {
+			for _, it := range q.stmt.items {
+				if it.alias != "" && strings.EqualFold(it.alias, v.name) {
+					return it.expr, nil
+				}
+			}
+		}
+	}
+	return e, nil
+}
+
+// substituteAliases - replaces select aliases used as bare column names in HAVING. Aggregate
+// arguments are left alone
+func (q *sqlQuery) substituteAliases(e sqlExpr) sqlExpr {
+	switch v := e.(type) {
+	case *sqlColumnRef:
+		// Column references are never ordinals, so there is no error
+		e, _ = q.selectItemRef(v, true)
+		return e
+	case *sqlFunc:
+		if _, ok := sqlAggregates[v.name]; ok {
+			return e
+		}
+	}
+
+	for _, c := range sqlChildren(e) {
+		*c = q.substituteAliases(*c)
+	}
+	return e
+}
+
+// walkSQLExpr - calls fn with a pointer to every child expression, recursively
+func walkSQLExpr(e sqlExpr, fn func(child *sqlExpr)) {
+	for _, c := range sqlChildren(e) {
+		fn(c)
+		walkSQLExpr(*c, fn)
+	}
+}
+
+// sqlChildren - returns pointers to the direct child expressions that are set
+func sqlChildren(e sqlExpr) []*sqlExpr {
+	var children []*sqlExpr
+	add := func(c *sqlExpr) {
+		if *c != nil {
+			children = append(children, c)
+		}
+	}
+
+	switch v := e.(type) {
+	case *sqlUnary:
+		add(&v.x)
+	case *sqlBinary:
+		add(&v.l)
+		add(&v.r)
+	case *sqlFunc:
+		for i := range v.args {
+			add(&v.args[i])
+		}
+	case *sqlIn:
+		add(&v.x)
+		for i := range v.list {
+			add(&v.list[i])
+		}
+	case *sqlBetween:
+		add(&v.x)
+		add(&v.lo)
+		add(&v.hi)
+	case *sqlIsNull:
+		add(&v.x)
+	case *sqlLike:
+		add(&v.x)
+		add(&v.pattern)
+	case *sqlCase:
+		add(&v.operand)
+		for i := range v.whens {
+			add(&v.whens[i].cond)
+			add(&v.whens[i].result)
+		}
+		add(&v.els)
+	case *sqlCast:
+		add(&v.x)
+	}
+	return children
+}
+
+// bindExpr - resolves the column references of an expression against the first ntables tables
+// and collects its aggregates
+func (q *sqlQuery) bindExpr(e sqlExpr, ntables int, allowAgg bool) error {
+	return q.bindNode(e, ntables, allowAgg, false)
+}
+
+func (q *sqlQuery) bindNode(e sqlExpr, ntables int, allowAgg, inAgg bool) error {
+	switch v := e.(type) {
+	case *sqlColumnRef:
+		if v.tidx != -1 {
+			return nil
+		}
+		return q.bindColumn(v, ntables)
+	case *sqlStar:
+		return errors.New("* is only allowed in the select list and COUNT(*)")
+	case *sqlFunc:
+		if _, ok := sqlAggregates[v.name]; ok {
+			switch {
+			case !allowAgg:
+				return fmt.Errorf("aggregate %s is not allowed here", v.name)
+			case inAgg:
+				return fmt.Errorf("aggregate %s cannot be nested", v.name)
+			case v.star && v.name != "COUNT":
+				return fmt.Errorf("%s(*) is not supported", v.name)
+			case !v.star && len(v.args) != 1:
+				return fmt.Errorf("%s requires one argument", v.name)
+			}
+			if !slices.Contains(q.aggs, v) {
+				q.aggs = append(q.aggs, v)
+			}
+			inAgg = true
+		} else if _, ok := sqlScalarFuncs[v.name]; !ok {
+			return fmt.Errorf("function %s is not supported", v.name)
+		}
+	}
+
+	for _, c := range sqlChildren(e) {
+		if err := q.bindNode(*c, ntables, allowAgg, inAgg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindColumn - resolves a column reference
+func (q *sqlQuery) bindColumn(c *sqlColumnRef, ntables int) error {
+	for ti := 0; ti < ntables; ti++ {
+		if c.table != "" && !strings.EqualFold(c.table, q.aliases[ti]) {
+			continue
+		}
+		ci := q.tables[ti].columnIndex(c.name)
+		if ci == -1 {
+			continue
+		}
+		if c.tidx != -1 {
+			return fmt.Errorf("column %s is ambiguous", c.name)
+		}
+		c.tidx, c.cidx = ti, ci
+	}
+
+	if c.tidx == -1 {
+		if c.table != "" {
+			return fmt.Errorf("column %s.%s does not exist", c.table, c.name)
+		}
+		return fmt.Errorf("column %s does not exist", c.name)
+	}
+	return nil
+}
+
+// run - executes the bound query
+func (q *sqlQuery) run() (*DataTable, error) {
+	s := q.stmt
+
+	rows, err := q.join()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.where != nil {
+		var kept [][]*Row
+		for _, src := range rows {
+			ok, err := q.truth(s.where, &sqlContext{src: src})
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				kept = append(kept, src)
+			}
+		}
+		rows = kept
+	}
+
+	var contexts []*sqlContext
+	if q.grouped {
+		if contexts, err = q.group(rows); err != nil {
+			return nil, err
+		}
+	} else {
+		contexts = make([]*sqlContext, len(rows))
+		for i, src := range rows {
+			contexts[i] = &sqlContext{src: src}
+		}
+	}
+
+	type outRow struct {
+		values []interface{}
+		order  []interface{}
+	}
+
+	var out []outRow
+	seen := make(map[string]bool)
+	for _, ctx := range contexts {
+		if s.having != nil {
+			ok, err := q.truth(s.having, ctx)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		r := outRow{values: make([]interface{}, len(s.items))}
+		for i, it := range s.items {
+			if r.values[i], err = q.eval(it.expr, ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		if s.distinct {
+			k := keyString(r.values...)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+
+		if len(s.orderBy) > 0 {
+			r.order = make([]interface{}, len(s.orderBy))
+			for i, o := range s.orderBy {
+				if r.order[i], err = q.eval(o.expr, ctx); err != nil {
+					return nil, err
+				}
+			}
+		}
+		out = append(out, r)
+	}
+
+	if len(s.orderBy) > 0 {
+		slices.SortStableFunc(out, func(a, b outRow) int {
+			for i, o := range s.orderBy {
+				c := compareValues(a.order[i], b.order[i])
+				if c != 0 {
+					if o.desc {
+						return -c
+					}
+					return c
+				}
+			}
+			return 0
+		})
+	}
+
+	if s.offset > 0 {
+		out = out[min(s.offset, len(out)):]
+	}
+	if s.limit >= 0 && s.limit < len(out) {
+		out = out[:s.limit]
+	}
+
+	res := NewDataTable("Query")
+	used := make(map[string]bool)
+	for i, it := range s.items {
+		col := Column{Name: it.alias}
+		if c, ok := it.expr.(*sqlColumnRef); ok {
+			col = q.tables[c.tidx].Columns[c.cidx]
+			col.PrimaryKey = false
+			if it.alias != "" {
+				col.Name = it.alias
+			}
+		} else {
+			// Expressions can yield int64 for some rows and float64 for others, so such columns widen to float64
+			for _, r := range out {
+				if r.values[i] == nil {
+					continue
+				}
+				t := reflect.TypeOf(r.values[i])
+				if col.Type == nil || t.Kind() == reflect.Float64 && isIntKind(col.Type.Kind()) {
+					col.Type = t
+				}
+			}
+			if col.Type == nil {
+				col.Type = reflect.TypeOf((*interface{})(nil)).Elem()
+			}
+		}
+		if col.Name == "" {
+			col.Name = it.text
+		}
+
+		// Output columns need distinct names
+		name := col.Name
+		for n := 1; used[strings.ToLower(col.Name)]; n++ {
+			col.Name = name + strconv.Itoa(n)
+		}
+		used[strings.ToLower(col.Name)] = true
+		res.AddColumns([]Column{col})
+	}
+
+	for _, r := range out {
+		nr := res.NewRow()
+		for i, v := range r.values {
+			if n, ok := v.(int64); ok && res.Columns[i].Type.Kind() == reflect.Float64 {
+				v = float64(n)
+			}
+			nr.Cells[i].Value = copyValue(v)
+		}
+		res.AddRow(&nr)
+	}
+	return res, nil
+}
+
+// join - builds the combined source rows of all tables
+func (q *sqlQuery) join() ([][]*Row, error) {
+	s := q.stmt
+
+	first := q.tables[0]
+	rows := make([][]*Row, len(first.Rows))
+	for i := range first.Rows {
+		src := make([]*Row, len(q.tables))
+		src[0] = &first.Rows[i]
+		rows[i] = src
+	}
+
+	for ti := 1; ti < len(q.tables); ti++ {
+		dt := q.tables[ti]
+		ref := s.from[ti]
+
+		// Equality conditions between the new table and the previous ones are used to build a hash
+		// index of the new table. The full condition is still checked for every candidate pair
+		var lkeys, rkeys []sqlExpr
+		if ref.on != nil {
+			for _, c := range splitSQLAnd(ref.on) {
+				b, ok := c.(*sqlBinary)
+				if !ok || b.op != "=" {
+					continue
+				}
+				lt, rt := sqlExprTables(b.l), sqlExprTables(b.r)
+				switch {
+				case lt == 1<<ti && rt != 0 && rt < 1<<ti:
+					lkeys, rkeys = append(lkeys, b.r), append(rkeys, b.l)
+				case rt == 1<<ti && lt != 0 && lt < 1<<ti:
+					lkeys, rkeys = append(lkeys, b.l), append(rkeys, b.r)
+				}
+			}
+		}
+
+		// = compares values of different classes, such as a number and text, as text, so a left row whose
+		// key classes differ from those of the indexed rows is checked against every row
+		var index map[string][]int
+		var classes []byte
+		if len(rkeys) > 0 {
+			index = make(map[string][]int, len(dt.Rows))
+			classes = make([]byte, len(rkeys))
+			src := make([]*Row, len(q.tables))
+			for i := range dt.Rows {
+				src[ti] = &dt.Rows[i]
+				k, kc, null, err := q.evalKey(rkeys, &sqlContext{src: src})
+				if err != nil {
+					return nil, err
+				}
+				if null {
+					continue
+				}
+				index[k] = append(index[k], i)
+				for j, c := range kc {
+					if classes[j] == 0 {
+						classes[j] = c
+					} else if classes[j] != c {
+						classes[j] = '*'
+					}
+				}
+			}
+		}
+
+		var joined [][]*Row
+		for _, left := range rows {
+			candidates := func(yield func(int) bool) {
+				for i := range dt.Rows {
+					if !yield(i) {
+						return
+					}
+				}
+			}
+			if index != nil {
+				k, kc, null, err := q.evalKey(lkeys, &sqlContext{src: left})
+				if err != nil {
+					return nil, err
+				}
+				if null || sameSQLKeyClasses(kc, classes) {
+					matches := index[k]
+					if null {
+						matches = nil
+					}
+					candidates = func(yield func(int) bool) {
+						for _, i := range matches {
+							if !yield(i) {
+								return
+							}
+						}
+					}
+				}
+			}
+
+			matched := false
+			for i := range candidates {
+				src := slices.Clone(left)
+				src[ti] = &dt.Rows[i]
+				if ref.on != nil {
+					ok, err := q.truth(ref.on, &sqlContext{src: src})
+					if err != nil {
+						return nil, err
+					}
+					if !ok {
+						continue
+					}
+				}
+				matched = true
+				joined = append(joined, src)
+			}
+
+			if !matched && ref.join == "LEFT" {
+				joined = append(joined, slices.Clone(left))
+			}
+		}
+		rows = joined
+	}
+
+	return rows, nil
+}
+
+// evalKey - evaluates key expressions into a hash join key and the class of each value. Returns true if any
+// value is null
+func (q *sqlQuery) evalKey(keys []sqlExpr, ctx *sqlContext) (string, []byte, bool, error) {
+	var b []byte
+	classes := make([]byte, len(keys))
+	for i, k := range keys {
+		v, err := q.eval(k, ctx)
+		if err != nil {
+			return "", nil, false, err
+		}
+		if v == nil {
+			return "", nil, true, nil
+		}
+		c, s := sqlJoinKey(v)
+		classes[i] = c
+		b = appendKeyPart(b, s)
+	}
+	return string(b), classes, false, nil
+}
+
+// sqlJoinKey - returns the class of a value and a key that is the same for the values of the class = finds
+// equal. Numbers are keyed by their float64 value, as = compares an integer and a float that way, and times
+// by their instant
+func sqlJoinKey(v interface{}) (byte, string) {
+	switch nv := normalizeValue(v).(type) {
+	case int64, uint64, float64:
+		f, _ := toFloat64(nv)
+		if f == 0 {
+			// Negative zero equals zero
+			f = 0
+		}
+		return 'n', strconv.FormatFloat(f, 'g', -1, 64)
+	case string:
+		return 's', nv
+	case bool:
+		return 'b', strconv.FormatBool(nv)
+	case time.Time:
+		return 't', nv.Format(time.RFC3339Nano)
+	default:
+		return 'x', fmt.Sprint(nv)
+	}
+}
+
+// sameSQLKeyClasses - reports whether the key classes of a row match those of all indexed rows. A key
+// no indexed row has a value for matches, as there is nothing to find
+func sameSQLKeyClasses(kc, classes []byte) bool {
+	for i, c := range kc {
+		if classes[i] != 0 && classes[i] != c {
+			return false
+		}
+	}
+	return true
+}
+
+// splitSQLAnd - splits an expression into the conditions joined by AND
+func splitSQLAnd(e sqlExpr) []sqlExpr {
+	if b, ok := e.(*sqlBinary); ok && b.op == "AND" {
+		return append(splitSQLAnd(b.l), splitSQLAnd(b.r)...)
+	}
+	return []sqlExpr{e}
+}
+
+// sqlExprTables - returns a bit mask of the tables an expression references
+func sqlExprTables(e sqlExpr) uint64 {
+	var mask uint64
+	check := func(e sqlExpr) {
+		if c, ok := e.(*sqlColumnRef); ok && c.tidx >= 0 && c.tidx < 64 {
+			mask |= 1 << c.tidx
+		}
+	}
+	check(e)
+	walkSQLExpr(e, func(child *sqlExpr) { check(*child) })
+	return mask
+}
+
+// group - splits the rows into groups and computes the aggregates of each group
+func (q *sqlQuery) group(rows [][]*Row) ([]*sqlContext, error) {
+	s := q.stmt
+
+	type group struct {
+		ctx  *sqlContext
+		aggs []*aggregator
+		seen []map[string]bool
+	}
+
+	var groups []*group
+	index := make(map[string]*group)
+
+	for _, src := range rows {
+		ctx := &sqlContext{src: src}
+		vals := make([]interface{}, len(s.groupBy))
+		for i, e := range s.groupBy {
+			v, err := q.eval(e, ctx)
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = v
+		}
+
+		k := keyString(vals...)
+		g, ok := index[k]
+		if !ok {
+			g = &group{ctx: &sqlContext{src: src, aggs: make(map[*sqlFunc]interface{})}}
+			for _, f := range q.aggs {
+				g.aggs = append(g.aggs, newAggregator(sqlAggregates[f.name]))
+				g.seen = append(g.seen, nil)
+			}
+			index[k] = g
+			groups = append(groups, g)
+		}
+
+		for i, f := range q.aggs {
+			if f.star {
+				g.aggs[i].add(true)
+				continue
+			}
+			v, err := q.eval(f.args[0], ctx)
+			if err != nil {
+				return nil, err
+			}
+			if f.distinct && v != nil {
+				if g.seen[i] == nil {
+					g.seen[i] = make(map[string]bool)
+				}
+				dk := keyString(v)
+				if g.seen[i][dk] {
+					continue
+				}
+				g.seen[i][dk] = true
+			}
+			g.aggs[i].add(v)
+		}
+	}
+
+	// Aggregates without GROUP BY return one row even with no input rows
+	if len(groups) == 0 && len(s.groupBy) == 0 {
+		g := &group{ctx: &sqlContext{src: make([]*Row, len(q.tables)), aggs: make(map[*sqlFunc]interface{})}}
+		for _, f := range q.aggs {
+			g.aggs = append(g.aggs, newAggregator(sqlAggregates[f.name]))
+		}
+		groups = append(groups, g)
+	}
+
+	contexts := make([]*sqlContext, len(groups))
+	for i, g := range groups {
+		for j, f := range q.aggs {
+			g.ctx.aggs[f] = g.aggs[j].result()
+		}
+		contexts[i] = g.ctx
+	}
+	return contexts, nil
+}
+
+// truth - evaluates a condition. Null is false
+func (q *sqlQuery) truth(e sqlExpr, ctx *sqlContext) (bool, error) {
+	v, err := q.eval(e, ctx)
+	if err != nil || v == nil {
+		return false, err
+	}
+	return sqlBool(v), nil
+}
+
+// sqlBool - converts a value to a boolean
+func sqlBool(v interface{}) bool {
+	switch b := normalizeValue(v).(type) {
+	case bool:
+		return b
+	case int64:
+		return b != 0
+	case float64:
+		return b != 0
+	case string:
+		s := strings.ToLower(b)
+		return s == "true" || s == "on" || s == "yes" || s == "1" || s == "-1"
+	}
+	return false
+}
+
+// eval - evaluates an expression
+func (q *sqlQuery) eval(e sqlExpr, ctx *sqlContext) (interface{}, error) {
+	switch v := e.(type) {
+	case *sqlLiteral:
+		return v.value, nil
+	case *sqlParam:
+		if v.index >= len(q.args) {
+			return nil, fmt.Errorf("missing value for parameter %d", v.index+1)
+		}
+		return q.args[v.index], nil
+	case *sqlColumnRef:
+		r := ctx.src[v.tidx]
+		if r == nil {
+			return nil, nil
+		}
+		return sqlCellValue(r, v.cidx), nil
+	case *sqlUnary:
+		x, err := q.eval(v.x, ctx)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		if v.op == "NOT" {
+			return !sqlBool(x), nil
+		}
+		return sqlArithmetic("-", int64(0), x)
+	case *sqlBinary:
+		return q.evalBinary(v, ctx)
+	case *sqlFunc:
+		if _, ok := sqlAggregates[v.name]; ok {
+			return ctx.aggs[v], nil
+		}
+		args := make([]interface{}, len(v.args))
+		for i, a := range v.args {
+			var err error
+			if args[i], err = q.eval(a, ctx); err != nil {
+				return nil, err
+			}
+		}
+		return sqlScalarFuncs[v.name](args)
+	case *sqlIn:
+		x, err := q.eval(v.x, ctx)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		found, hasNull := false, false
+		for _, item := range v.list {
+			iv, err := q.eval(item, ctx)
+			if err != nil {
+				return nil, err
+			}
+			if iv == nil {
+				hasNull = true
+				continue
+			}
+			if compareValues(x, iv) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found && hasNull {
+			return nil, nil
+		}
+		return found != v.not, nil
+	case *sqlBetween:
+		x, err := q.eval(v.x, ctx)
+		if err != nil {
+			return nil, err
+		}
+		lo, err := q.eval(v.lo, ctx)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := q.eval(v.hi, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if x == nil || lo == nil || hi == nil {
+			return nil, nil
+		}
+		in := compareValues(x, lo) >= 0 && compareValues(x, hi) <= 0
+		return in != v.not, nil
+	case *sqlIsNull:
+		x, err := q.eval(v.x, ctx)
+		if err != nil {
+			return nil, err
+		}
+		return (x == nil) != v.not, nil
+	case *sqlLike:
+		x, err := q.eval(v.x, ctx)
+		if err != nil {
+			return nil, err
+		}
+		pat, err := q.eval(v.pattern, ctx)
+		if err != nil || x == nil || pat == nil {
+			return nil, err
+		}
+		re, err := likePattern(sqlString(pat))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(sqlString(x)) != v.not, nil
+	case *sqlCase:
+		var operand interface{}
+		if v.operand != nil {
+			var err error
+			if operand, err = q.eval(v.operand, ctx); err != nil {
+				return nil, err
+			}
+		}
+		for _, w := range v.whens {
+			c, err := q.eval(w.cond, ctx)
+			if err != nil {
+				return nil, err
+			}
+			match := false
+			if v.operand != nil {
+				match = operand != nil && c != nil && compareValues(operand, c) == 0
+			} else {
+				match = c != nil && sqlBool(c)
+			}
+			if match {
+				return q.eval(w.result, ctx)
+			}
+		}
+		if v.els != nil {
+			return q.eval(v.els, ctx)
+		}
+		return nil, nil
+	case *sqlCast:
+		x, err := q.eval(v.x, ctx)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		return sqlCastValue(x, v.toType)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", e)
+}
+
+func (q *sqlQuery) evalBinary(b *sqlBinary, ctx *sqlContext) (interface{}, error) {
+	l, err := q.eval(b.l, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// AND and OR follow three valued logic and skip the right side when the left decides
+	switch b.op {
+	case "AND":
+		if l != nil && !sqlBool(l) {
+			return false, nil
+		}
+		r, err := q.eval(b.r, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil && !sqlBool(r) {
+			return false, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return true, nil
+	case "OR":
+		if l != nil && sqlBool(l) {
+			return true, nil
+		}
+		r, err := q.eval(b.r, ctx)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil && sqlBool(r) {
+			return true, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return false, nil
+	}
+
+	r, err := q.eval(b.r, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	switch b.op {
+	case "=":
+		return compareValues(l, r) == 0, nil
+	case "<>":
+		return compareValues(l, r) != 0, nil
+	case "<":
+		return compareValues(l, r) < 0, nil
+	case "<=":
+		return compareValues(l, r) <= 0, nil
+	case ">":
+		return compareValues(l, r) > 0, nil
+	case ">=":
+		return compareValues(l, r) >= 0, nil
+	case "||":
+		return sqlString(l) + sqlString(r), nil
+	}
+	return sqlArithmetic(b.op, l, r)
+}
+
+// sqlCellValue - reads a cell the way Row.ValueByName does, so DECIMAL text becomes float64
+// and other []uint8 values except IMAGE become strings
+func sqlCellValue(r *Row, index int) interface{} {
+	if index >= len(r.Cells) {
+		return nil
+	}
+	c := r.Cells[index]
+	if b, ok := c.Value.([]uint8); ok {
+		switch strings.ToUpper(c.DBColumnType) {
+		case "IMAGE":
+			return b
+		case "DECIMAL":
+			f, _ := strconv.ParseFloat(string(b), 64)
+			return f
+		}
+		return string(b)
+	}
+	return c.Value
+}
+
+// sqlNumber - converts a value to int64 or float64. Text is parsed as a number. Floats stay float64
+// even when they hold a whole number
+func sqlNumber(v interface{}) (interface{}, bool) {
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	switch n := normalizeValue(v).(type) {
+	case int64:
+		return n, true
+	case float64:
+		return n, true
+	case uint64:
+		return float64(n), true
+	case bool:
+		if n {
+			return int64(1), true
+		}
+		return int64(0), true
+	case string:
+		s := strings.TrimSpace(n)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// sqlArithmetic - applies an arithmetic operator. Integers stay integers unless a float is involved.
+// Adding two values where one is non numeric text concatenates them
+func sqlArithmetic(op string, l, r interface{}) (interface{}, error) {
+	ln, lok := sqlNumber(l)
+	rn, rok := sqlNumber(r)
+	if !lok || !rok {
+		_, ls := l.(string)
+		_, rs := r.(string)
+		if op == "+" && (ls || rs) {
+			return sqlString(l) + sqlString(r), nil
+		}
+		return nil, fmt.Errorf("cannot apply %s to %v and %v", op, l, r)
+	}
+
+	li, lint := ln.(int64)
+	ri, rint := rn.(int64)
+	if lint && rint {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "/", "%":
+			if ri == 0 {
+				return nil, errors.New("division by zero")
+			}
+			if op == "/" {
+				return li / ri, nil
+			}
+			return li % ri, nil
+		}
+	}
+
+	lf, _ := toFloat64(ln)
+	rf, _ := toFloat64(rn)
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return math.Mod(lf, rf), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+// sqlString - converts a value to text
+func sqlString(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case []uint8:
+		return string(s)
+	case time.Time:
+		return s.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(s), 'f', -1, 32)
+	}
+	return fmt.Sprint(v)
+}
+
+// likePattern - converts a LIKE pattern to a case insensitive regular expression
+func likePattern(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// sqlCastValue - converts a value to a type named in a CAST
+func sqlCastValue(v interface{}, toType string) (interface{}, error) {
+	switch toType {
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT":
+		n, ok := sqlNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot convert %v to %s", v, toType)
+		}
+		if f, ok := n.(float64); ok {
+			return int64(f), nil
+		}
+		return n, nil
+	case "FLOAT", "REAL", "DOUBLE", "DECIMAL", "NUMERIC", "MONEY":
+		n, ok := sqlNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("cannot convert %v to %s", v, toType)
+		}
+		f, _ := toFloat64(n)
+		return f, nil
+	case "VARCHAR", "NVARCHAR", "CHAR", "NCHAR", "TEXT", "NTEXT", "STRING":
+		return sqlString(v), nil
+	case "BIT", "BOOL", "BOOLEAN":
+		return sqlBool(v), nil
+	case "DATE", "DATETIME", "DATETIME2", "TIMESTAMP":
+		if t, ok := v.(time.Time); ok {
+			if toType == "DATE" {
+				return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()), nil
+			}
+			return t, nil
+		}
+		s := sqlString(v)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot convert %v to %s", v, toType)
+	}
+	return nil, fmt.Errorf("unsupported type %s", toType)
+}
+
+// Scalar functions. Most return null when an argument is null
+var sqlScalarFuncs map[string]func(args []interface{}) (interface{}, error)
+
+func init() {
+	str1 := func(name string, fn func(s string) interface{}) func([]interface{}) (interface{}, error) {
+		return func(args []interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s requires one argument", name)
+			}
+			if args[0] == nil {
+				return nil, nil
+			}
+			return fn(sqlString(args[0])), nil
+		}
+	}
+
+	num1 := func(name string, fn func(f float64) float64) func([]interface{}) (interface{}, error) {
+		return func(args []interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s requires one argument", name)
+			}
+			if args[0] == nil {
+				return nil, nil
+			}
+			n, ok := sqlNumber(args[0])
+			if !ok {
+				return nil, fmt.Errorf("%s requires a number", name)
+			}
+			if i, ok := n.(int64); ok {
+				return int64(fn(float64(i))), nil
+			}
+			return fn(n.(float64)), nil
+		}
+	}
+
+	datePart := func(name string, fn func(t time.Time) int) func([]interface{}) (interface{}, error) {
+		return func(args []interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("%s requires one argument", name)
+			}
+			if args[0] == nil {
+				return nil, nil
+			}
+			t, err := sqlCastValue(args[0], "DATETIME")
+			if err != nil {
+				return nil, err
+			}
+			return int64(fn(t.(time.Time))), nil
+		}
+	}
+
+	coalesce := func(args []interface{}) (interface{}, error) {
+		for _, a := range args {
+			if a != nil {
+				return a, nil
+			}
+		}
+		return nil, nil
+	}
+
+	length := str1("LENGTH", func(s string) interface{} { return int64(len([]rune(s))) })
+
+	substr := func(args []interface{}) (interface{}, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, errors.New("SUBSTRING requires two or three arguments")
+		}
+		for _, a := range args {
+			if a == nil {
+				return nil, nil
+			}
+		}
+		s := []rune(sqlString(args[0]))
+		sn, ok := sqlNumber(args[1])
+		if !ok {
+			return nil, errors.New("SUBSTRING requires a numeric start")
+		}
+		sf, _ := toFloat64(sn)
+		start := int(sf) - 1
+		end := len(s)
+		if len(args) == 3 {
+			ln, ok := sqlNumber(args[2])
+			if !ok {
+				return nil, errors.New("SUBSTRING requires a numeric length")
+			}
+			lf, _ := toFloat64(ln)
+			end = start + int(lf)
+		}
+		start = max(start, 0)
+		end = min(end, len(s))
+		if start >= end {
+			return "", nil
+		}
+		return string(s[start:end]), nil
+	}
+
+	sqlScalarFuncs = map[string]func(args []interface{}) (interface{}, error){
+		"UPPER":     str1("UPPER", func(s string) interface{} { return strings.ToUpper(s) }),
+		"LOWER":     str1("LOWER", func(s string) interface{} { return strings.ToLower(s) }),
+		"TRIM":      str1("TRIM", func(s string) interface{} { return strings.TrimSpace(s) }),
+		"LTRIM":     str1("LTRIM", func(s string) interface{} { return strings.TrimLeft(s, " \t\r\n") }),
+		"RTRIM":     str1("RTRIM", func(s string) interface{} { return strings.TrimRight(s, " \t\r\n") }),
+		"LENGTH":    length,
+		"LEN":       length,
+		"SUBSTR":    substr,
+		"SUBSTRING": substr,
+		"COALESCE":  coalesce,
+		"IFNULL":    coalesce,
+		"ISNULL":    coalesce,
+		"ABS":       num1("ABS", math.Abs),
+		"FLOOR":     num1("FLOOR", math.Floor),
+		"CEIL":      num1("CEIL", math.Ceil),
+		"CEILING":   num1("CEILING", math.Ceil),
+		"YEAR":      datePart("YEAR", func(t time.Time) int { return t.Year() }),
+		"MONTH":     datePart("MONTH", func(t time.Time) int { return int(t.Month()) }),
+		"DAY":       datePart("DAY", func(t time.Time) int { return t.Day() }),
+		"REPLACE": func(args []interface{}) (interface{}, error) {
+			if len(args) != 3 {
+				return nil, errors.New("REPLACE requires three arguments")
+			}
+			if args[0] == nil || args[1] == nil || args[2] == nil {
+				return nil, nil
+			}
+			return strings.ReplaceAll(sqlString(args[0]), sqlString(args[1]), sqlString(args[2])), nil
+		},
+		"CONCAT": func(args []interface{}) (interface{}, error) {
+			var sb strings.Builder
+			for _, a := range args {
+				if a != nil {
+					sb.WriteString(sqlString(a))
+				}
+			}
+			return sb.String(), nil
+		},
+		"NULLIF": func(args []interface{}) (interface{}, error) {
+			if len(args) != 2 {
+				return nil, errors.New("NULLIF requires two arguments")
+			}
+			if args[0] != nil && args[1] != nil && compareValues(args[0], args[1]) == 0 {
+				return nil, nil
+			}
+			return args[0], nil
+		},
+		"ROUND": func(args []interface{}) (interface{}, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, errors.New("ROUND requires one or two arguments")
+			}
+			if args[0] == nil {
+				return nil, nil
+			}
+			n, ok := sqlNumber(args[0])
+			if !ok {
+				return nil, errors.New("ROUND requires a number")
+			}
+			if i, ok := n.(int64); ok {
+				return i, nil
+			}
+			places := 0.0
+			if len(args) == 2 && args[1] != nil {
+				pn, ok := sqlNumber(args[1])
+				if !ok {
+					return nil, errors.New("ROUND requires numeric places")
+				}
+				places, _ = toFloat64(pn)
+			}
+			p := math.Pow(10, places)
+			return math.Round(n.(float64)*p) / p, nil
+		},
+	}
+}
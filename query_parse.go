@@ -0,0 +1,927 @@
+package datatable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Tokens of the query language
+type sqlTokenKind int
+
+const (
+	sqlTokEOF sqlTokenKind = iota
+	sqlTokIdent
+	sqlTokQuotedIdent
+	sqlTokNumber
+	sqlTokString
+	sqlTokSymbol
+	sqlTokParam
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+	pos  int
+	end  int
+}
+
+// Words that end an expression or a table reference, so they cannot be used as an alias without AS
+var sqlReserved = map[string]bool{
+	"SELECT": true, "DISTINCT": true, "TOP": true, "FROM": true, "WHERE": true, "GROUP": true,
+	"BY": true, "HAVING": true, "ORDER": true, "LIMIT": true, "OFFSET": true, "JOIN": true,
+	"INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true, "CROSS": true,
+	"ON": true, "AS": true, "AND": true, "OR": true, "NOT": true, "ASC": true, "DESC": true,
+	"UNION": true, "CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+	"IS": true, "IN": true, "LIKE": true, "BETWEEN": true, "NULL": true,
+}
+
+// sqlLex - splits a query into tokens
+func sqlLex(src string) ([]sqlToken, error) {
+	var toks []sqlToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && i+1 < len(src) && src[i+1] == '-':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '\'':
+			var sb strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(src) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if src[i] == '\'' {
+					if i+1 < len(src) && src[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			toks = append(toks, sqlToken{kind: sqlTokString, text: sb.String(), pos: start, end: i})
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			start := i
+			j := strings.IndexByte(src[i+1:], closing)
+			if j == -1 {
+				return nil, fmt.Errorf("unterminated identifier at position %d", start)
+			}
+			i += j + 2
+			toks = append(toks, sqlToken{kind: sqlTokQuotedIdent, text: src[start+1 : i-1], pos: start, end: i})
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				i++
+				if i < len(src) && (src[i] == '+' || src[i] == '-') {
+					i++
+				}
+				for i < len(src) && src[i] >= '0' && src[i] <= '9' {
+					i++
+				}
+			}
+			toks = append(toks, sqlToken{kind: sqlTokNumber, text: src[start:i], pos: start, end: i})
+		case c == '?' || c == '$' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			start := i
+			i++
+			for c == '$' && i < len(src) && src[i] >= '0' && src[i] <= '9' {
+				i++
+			}
+			toks = append(toks, sqlToken{kind: sqlTokParam, text: src[start:i], pos: start, end: i})
+		case c == '_' || c == '@' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			start := i
+			for i < len(src) && (src[i] == '_' || src[i] == '@' || src[i] == '$' || src[i] >= 0x80 ||
+				unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			toks = append(toks, sqlToken{kind: sqlTokIdent, text: src[start:i], pos: start, end: i})
+		default:
+			start := i
+			op := string(c)
+			if i+1 < len(src) {
+				switch two := src[i : i+2]; two {
+				case "<=", ">=", "<>", "!=", "||":
+					op = two
+				}
+			}
+			if !strings.Contains("(),.*+-/%=<>;", op) && len(op) == 1 {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			i += len(op)
+			toks = append(toks, sqlToken{kind: sqlTokSymbol, text: op, pos: start, end: i})
+		}
+	}
+	toks = append(toks, sqlToken{kind: sqlTokEOF, pos: len(src), end: len(src)})
+	return toks, nil
+}
+
+// Expressions of the query language
+type (
+	sqlExpr interface{}
+
+	sqlLiteral struct {
+		value interface{}
+	}
+
+	sqlColumnRef struct {
+		table string
+		name  string
+		tidx  int // index of the source table, set when bound
+		cidx  int // index of the column in the source table, set when bound
+	}
+
+	sqlStar struct {
+		table string
+	}
+
+	sqlUnary struct {
+		op string
+		x  sqlExpr
+	}
+
+	sqlBinary struct {
+		op   string
+		l, r sqlExpr
+	}
+
+	sqlFunc struct {
+		name     string
+		args     []sqlExpr
+		star     bool
+		distinct bool
+	}
+
+	sqlIn struct {
+		x    sqlExpr
+		list []sqlExpr
+		not  bool
+	}
+
+	sqlBetween struct {
+		x, lo, hi sqlExpr
+		not       bool
+	}
+
+	sqlIsNull struct {
+		x   sqlExpr
+		not bool
+	}
+
+	sqlLike struct {
+		x, pattern sqlExpr
+		not        bool
+	}
+
+	sqlCase struct {
+		operand sqlExpr
+		whens   []sqlWhen
+		els     sqlExpr
+	}
+
+	sqlWhen struct {
+		cond, result sqlExpr
+	}
+
+	sqlCast struct {
+		x      sqlExpr
+		toType string
+	}
+
+	sqlParam struct {
+		index int
+	}
+)
+
+// A parsed SELECT statement
+type sqlSelect struct {
+	distinct bool
+	items    []sqlSelectItem
+	from     []sqlTableRef
+	where    sqlExpr
+	groupBy  []sqlExpr
+	having   sqlExpr
+	orderBy  []sqlOrderItem
+	limit    int
+	offset   int
+}
+
+type sqlSelectItem struct {
+	expr  sqlExpr
+	alias string
+	text  string
+}
+
+type sqlTableRef struct {
+	name  string
+	alias string
+	join  string // "" for the first table, INNER, LEFT or CROSS
+	on    sqlExpr
+}
+
+type sqlOrderItem struct {
+	expr sqlExpr
+	desc bool
+}
+
+type sqlParser struct {
+	src     string
+	toks    []sqlToken
+	p       int
+	nparams int // number of ? placeholders read so far
+}
+
+// parseSQL - parses a SELECT statement
+func parseSQL(src string) (*sqlSelect, error) {
+	toks, err := sqlLex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &sqlParser{src: src, toks: toks}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+
+	p.acceptSymbol(";")
+	if p.peek().kind != sqlTokEOF {
+		return nil, p.errorf("unexpected %s", p.peek().text)
+	}
+	return stmt, nil
+}
+
+func (p *sqlParser) peek() sqlToken {
+	return p.toks[p.p]
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.toks[p.p]
+	if t.kind != sqlTokEOF {
+		p.p++
+	}
+	return t
+}
+
+func (p *sqlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("syntax error at position %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+func (p *sqlParser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == sqlTokIdent && strings.EqualFold(t.text, kw)
+}
+
+func (p *sqlParser) isKeywordAt(offset int, kw string) bool {
+	if p.p+offset >= len(p.toks) {
+		return false
+	}
+	t := p.toks[p.p+offset]
+	return t.kind == sqlTokIdent && strings.EqualFold(t.text, kw)
+}
+
+func (p *sqlParser) acceptKeyword(kw string) bool {
+	if p.isKeyword(kw) {
+		p.p++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expectKeyword(kw string) error {
+	if !p.acceptKeyword(kw) {
+		return p.errorf("expected %s", kw)
+	}
+	return nil
+}
+
+func (p *sqlParser) isSymbol(s string) bool {
+	t := p.peek()
+	return t.kind == sqlTokSymbol && t.text == s
+}
+
+func (p *sqlParser) acceptSymbol(s string) bool {
+	if p.isSymbol(s) {
+		p.p++
+		return true
+	}
+	return false
+}
+
+func (p *sqlParser) expectSymbol(s string) error {
+	if !p.acceptSymbol(s) {
+		return p.errorf("expected %s", s)
+	}
+	return nil
+}
+
+// identifier - reads a plain or quoted identifier
+func (p *sqlParser) identifier() (string, error) {
+	t := p.peek()
+	if t.kind == sqlTokQuotedIdent || t.kind == sqlTokIdent && !sqlReserved[strings.ToUpper(t.text)] {
+		p.p++
+		return t.text, nil
+	}
+	return "", p.errorf("expected an identifier")
+}
+
+// alias - reads an optional alias, with or without AS
+func (p *sqlParser) alias() (string, error) {
+	if p.acceptKeyword("AS") {
+		if p.peek().kind == sqlTokString {
+			return p.next().text, nil
+		}
+		return p.identifier()
+	}
+	t := p.peek()
+	if t.kind == sqlTokQuotedIdent || t.kind == sqlTokIdent && !sqlReserved[strings.ToUpper(t.text)] {
+		p.p++
+		return t.text, nil
+	}
+	return "", nil
+}
+
+func (p *sqlParser) integer() (int, error) {
+	t := p.peek()
+	if t.kind != sqlTokNumber {
+		return 0, p.errorf("expected a number")
+	}
+	n, err := strconv.Atoi(t.text)
+	if err != nil || n < 0 {
+		return 0, p.errorf("invalid number %s", t.text)
+	}
+	p.p++
+	return n, nil
+}
+
+func (p *sqlParser) parseSelect() (*sqlSelect, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	stmt := &sqlSelect{limit: -1}
+	stmt.distinct = p.acceptKeyword("DISTINCT")
+	if !stmt.distinct {
+		p.acceptKeyword("ALL")
+	}
+
+	if p.acceptKeyword("TOP") {
+		n, err := p.integer()
+		if err != nil {
+			return nil, err
+		}
+		stmt.limit = n
+	}
+
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.items = append(stmt.items, item)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if err := p.parseFrom(stmt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.acceptKeyword("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.groupBy = append(stmt.groupBy, e)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("HAVING") {
+		if stmt.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := sqlOrderItem{expr: e}
+			if p.acceptKeyword("DESC") {
+				item.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, item)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		if stmt.limit, err = p.integer(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("OFFSET") {
+		if stmt.offset, err = p.integer(); err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword("ROWS") {
+			p.acceptKeyword("ROW")
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *sqlParser) parseSelectItem() (sqlSelectItem, error) {
+	// * and table.*
+	if p.acceptSymbol("*") {
+		return sqlSelectItem{expr: &sqlStar{}}, nil
+	}
+	if t := p.peek(); (t.kind == sqlTokIdent || t.kind == sqlTokQuotedIdent) &&
+		p.toks[p.p+1].kind == sqlTokSymbol && p.toks[p.p+1].text == "." &&
+		p.toks[p.p+2].kind == sqlTokSymbol && p.toks[p.p+2].text == "*" {
+		p.p += 3
+		return sqlSelectItem{expr: &sqlStar{table: t.text}}, nil
+	}
+
+	start := p.peek().pos
+	e, err := p.parseExpr()
+	if err != nil {
+		return sqlSelectItem{}, err
+	}
+	text := strings.TrimSpace(p.src[start:p.toks[p.p-1].end])
+
+	alias, err := p.alias()
+	if err != nil {
+		return sqlSelectItem{}, err
+	}
+	return sqlSelectItem{expr: e, alias: alias, text: text}, nil
+}
+
+func (p *sqlParser) parseTableRef(join string) (sqlTableRef, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return sqlTableRef{}, err
+	}
+	// Schema qualified names keep the last part
+	for p.acceptSymbol(".") {
+		if name, err = p.identifier(); err != nil {
+			return sqlTableRef{}, err
+		}
+	}
+
+	alias, err := p.alias()
+	if err != nil {
+		return sqlTableRef{}, err
+	}
+	if alias == "" {
+		alias = name
+	}
+	return sqlTableRef{name: name, alias: alias, join: join}, nil
+}
+
+func (p *sqlParser) parseFrom(stmt *sqlSelect) error {
+	first, err := p.parseTableRef("")
+	if err != nil {
+		return err
+	}
+	stmt.from = append(stmt.from, first)
+
+	for {
+		join := ""
+		switch {
+		case p.acceptSymbol(","):
+			join = "CROSS"
+		case p.acceptKeyword("CROSS"):
+			if err := p.expectKeyword("JOIN"); err != nil {
+				return err
+			}
+			join = "CROSS"
+		case p.acceptKeyword("INNER"):
+			if err := p.expectKeyword("JOIN"); err != nil {
+				return err
+			}
+			join = "INNER"
+		case p.acceptKeyword("JOIN"):
+			join = "INNER"
+		case p.acceptKeyword("LEFT"):
+			p.acceptKeyword("OUTER")
+			if err := p.expectKeyword("JOIN"); err != nil {
+				return err
+			}
+			join = "LEFT"
+		case p.isKeyword("RIGHT") || p.isKeyword("FULL"):
+			return p.errorf("%s JOIN is not supported", strings.ToUpper(p.peek().text))
+		default:
+			return nil
+		}
+
+		ref, err := p.parseTableRef(join)
+		if err != nil {
+			return err
+		}
+		if join != "CROSS" {
+			if err := p.expectKeyword("ON"); err != nil {
+				return err
+			}
+			if ref.on, err = p.parseExpr(); err != nil {
+				return err
+			}
+		}
+		stmt.from = append(stmt.from, ref)
+	}
+}
+
+func (p *sqlParser) parseExpr() (sqlExpr, error) {
+	return p.parseOr()
+}
+
+func (p *sqlParser) parseOr() (sqlExpr, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &sqlBinary{op: "OR", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *sqlParser) parseAnd() (sqlExpr, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = &sqlBinary{op: "AND", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *sqlParser) parseNot() (sqlExpr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &sqlUnary{op: "NOT", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *sqlParser) parseComparison() (sqlExpr, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	if t.kind == sqlTokSymbol {
+		switch t.text {
+		case "=", "<>", "!=", "<", "<=", ">", ">=":
+			p.p++
+			r, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			op := t.text
+			if op == "!=" {
+				op = "<>"
+			}
+			return &sqlBinary{op: op, l: l, r: r}, nil
+		}
+	}
+
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &sqlIsNull{x: l, not: not}, nil
+	}
+
+	not := false
+	if p.isKeyword("NOT") && (p.isKeywordAt(1, "IN") || p.isKeywordAt(1, "BETWEEN") || p.isKeywordAt(1, "LIKE")) {
+		p.p++
+		not = true
+	}
+
+	switch {
+	case p.acceptKeyword("IN"):
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		in := &sqlIn{x: l, not: not}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			in.list = append(in.list, e)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return in, nil
+	case p.acceptKeyword("BETWEEN"):
+		lo, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		hi, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &sqlBetween{x: l, lo: lo, hi: hi, not: not}, nil
+	case p.acceptKeyword("LIKE"):
+		pat, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &sqlLike{x: l, pattern: pat, not: not}, nil
+	}
+
+	return l, nil
+}
+
+func (p *sqlParser) parseAdditive() (sqlExpr, error) {
+	l, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isSymbol("+") || p.isSymbol("-") || p.isSymbol("||") {
+		op := p.next().text
+		r, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l = &sqlBinary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *sqlParser) parseMultiplicative() (sqlExpr, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isSymbol("*") || p.isSymbol("/") || p.isSymbol("%") {
+		op := p.next().text
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = &sqlBinary{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *sqlParser) parseUnary() (sqlExpr, error) {
+	if p.acceptSymbol("-") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if lit, ok := x.(*sqlLiteral); ok {
+			switch v := lit.value.(type) {
+			case int64:
+				return &sqlLiteral{value: -v}, nil
+			case float64:
+				return &sqlLiteral{value: -v}, nil
+			}
+		}
+		return &sqlUnary{op: "-", x: x}, nil
+	}
+	if p.acceptSymbol("+") {
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+func (p *sqlParser) parsePrimary() (sqlExpr, error) {
+	t := p.peek()
+
+	switch t.kind {
+	case sqlTokNumber:
+		p.p++
+		if !strings.ContainsAny(t.text, ".eE") {
+			if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+				return &sqlLiteral{value: n}, nil
+			}
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", t.text, t.pos)
+		}
+		return &sqlLiteral{value: f}, nil
+	case sqlTokString:
+		p.p++
+		return &sqlLiteral{value: t.text}, nil
+	case sqlTokSymbol:
+		if p.acceptSymbol("(") {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectSymbol(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+		return nil, p.errorf("unexpected %s", t.text)
+	case sqlTokParam:
+		p.p++
+		if t.text == "?" {
+			p.nparams++
+			return &sqlParam{index: p.nparams - 1}, nil
+		}
+		n, err := strconv.Atoi(t.text[1:])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid parameter %s at position %d", t.text, t.pos)
+		}
+		return &sqlParam{index: n - 1}, nil
+	case sqlTokEOF:
+		return nil, p.errorf("unexpected end of query")
+	}
+
+	if t.kind == sqlTokIdent {
+		switch strings.ToUpper(t.text) {
+		case "NULL":
+			p.p++
+			return &sqlLiteral{value: nil}, nil
+		case "TRUE":
+			p.p++
+			return &sqlLiteral{value: true}, nil
+		case "FALSE":
+			p.p++
+			return &sqlLiteral{value: false}, nil
+		case "CASE":
+			p.p++
+			return p.parseCase()
+		case "CAST":
+			if p.toks[p.p+1].kind == sqlTokSymbol && p.toks[p.p+1].text == "(" {
+				p.p += 2
+				return p.parseCast()
+			}
+		}
+	}
+
+	// Function call
+	if t.kind == sqlTokIdent && p.toks[p.p+1].kind == sqlTokSymbol && p.toks[p.p+1].text == "(" {
+		p.p += 2
+		fn := &sqlFunc{name: strings.ToUpper(t.text)}
+		if p.acceptSymbol("*") {
+			fn.star = true
+		} else if !p.isSymbol(")") {
+			fn.distinct = p.acceptKeyword("DISTINCT")
+			for {
+				e, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				fn.args = append(fn.args, e)
+				if !p.acceptSymbol(",") {
+					break
+				}
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return fn, nil
+	}
+
+	// Column reference
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptSymbol(".") {
+		col, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		return &sqlColumnRef{table: name, name: col, tidx: -1, cidx: -1}, nil
+	}
+	return &sqlColumnRef{name: name, tidx: -1, cidx: -1}, nil
+}
+
+func (p *sqlParser) parseCase() (sqlExpr, error) {
+	c := &sqlCase{}
+	var err error
+	if !p.isKeyword("WHEN") {
+		if c.operand, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	for p.acceptKeyword("WHEN") {
+		var w sqlWhen
+		if w.cond, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		if w.result, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, w)
+	}
+	if len(c.whens) == 0 {
+		return nil, p.errorf("expected WHEN")
+	}
+
+	if p.acceptKeyword("ELSE") {
+		if c.els, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("END"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *sqlParser) parseCast() (sqlExpr, error) {
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	if t.kind != sqlTokIdent {
+		return nil, p.errorf("expected a type name")
+	}
+	// Ignore length and precision such as VARCHAR(20) or DECIMAL(10,2)
+	if p.acceptSymbol("(") {
+		for !p.acceptSymbol(")") {
+			if p.next().kind == sqlTokEOF {
+				return nil, p.errorf("expected )")
+			}
+		}
+	}
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	return &sqlCast{x: x, toType: strings.ToUpper(t.text)}, nil
+}
@@ -0,0 +1,276 @@
+package datatable
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func queryRows(dt *DataTable) string {
+	var rows [][]interface{}
+	for _, r := range dt.Rows {
+		var vals []interface{}
+		for i := range r.Cells {
+			vals = append(vals, r.Value(i))
+		}
+		rows = append(rows, vals)
+	}
+	return fmt.Sprint(rows)
+}
+
+func TestQueryTables(t *testing.T) {
+	sales := NewDataTable("Sales")
+	sales.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "RegionID", Type: reflect.TypeOf(0)},
+		{Name: "Amount", Type: reflect.TypeOf([]byte{}), DBType: "DECIMAL"},
+		{Name: "Item", Type: reflect.TypeOf("")},
+	})
+	for _, v := range []struct {
+		id, region int
+		amount     string
+		item       interface{}
+	}{
+		{1, 1, "10.50", "Apple"}, {2, 1, "20", "Banana"}, {3, 2, "5", "apricot"},
+		{4, 3, "7.25", nil}, {5, 2, "1", "Cherry"}, {6, 9, "100", "Orphan"},
+	} {
+		r := sales.NewRow()
+		r.Cells[0].Value = v.id
+		r.Cells[1].Value = v.region
+		r.Cells[2].Value = []byte(v.amount)
+		r.Cells[3].Value = v.item
+		sales.AddRow(&r)
+	}
+
+	regions := NewDataTable("Regions")
+	regions.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0))},
+		{Name: "Region", Type: reflect.TypeOf("")},
+	})
+	for i, name := range []string{"North", "South", "East"} {
+		r := regions.NewRow()
+		r.Cells[0].Value = int64(i + 1)
+		r.Cells[1].Value = name
+		regions.AddRow(&r)
+	}
+	tables := map[string]*DataTable{"sales": sales, "regions": regions}
+
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			"SELECT region, SUM(amount) AS total FROM sales s JOIN regions r ON s.RegionID = r.ID WHERE s.amount > 1 GROUP BY region ORDER BY 2 DESC",
+			"[[North 30.5] [East 7.25] [South 5]]",
+		},
+		{
+			"SELECT r.Region, COUNT(s.ID) FROM regions r LEFT JOIN sales s ON s.RegionID = r.ID AND s.Amount > 6 GROUP BY r.Region HAVING COUNT(s.ID) < 2 ORDER BY r.Region",
+			"[[East 1] [South 0]]",
+		},
+		{
+			"SELECT id, UPPER(item) name, amount * 2 AS doubled FROM sales WHERE item LIKE 'a%' OR item IS NULL ORDER BY id",
+			"[[1 APPLE 21] [3 APRICOT 10] [4 <nil> 14.5]]",
+		},
+		{
+			"SELECT TOP 2 ID, CASE WHEN Amount >= 10 THEN 'big' ELSE 'small' END size FROM Sales ORDER BY Amount DESC",
+			"[[6 big] [2 big]]",
+		},
+		{
+			"SELECT DISTINCT RegionID FROM sales WHERE RegionID IN (1, 2, 9) AND NOT RegionID = 9 ORDER BY RegionID LIMIT 5 OFFSET 1",
+			"[[2]]",
+		},
+		{
+			"SELECT COUNT(*), COUNT(DISTINCT RegionID), MIN(item), MAX(ID) FROM sales",
+			"[[6 4 Apple 6]]",
+		},
+		{
+			"SELECT ID, COALESCE(item, 'none') || '!', SUBSTRING(item, 2, 3), LENGTH(item), CAST(amount AS INT) FROM sales WHERE ID BETWEEN 3 AND 4",
+			"[[3 apricot! pri 7 5] [4 none! <nil> <nil> 7]]",
+		},
+		{
+			"SELECT * FROM regions WHERE ID = 2",
+			"[[2 South]]",
+		},
+	}
+
+	for _, tt := range tests {
+		res, err := QueryTables(tt.sql, tables)
+		if err != nil {
+			t.Errorf("%s: %v", tt.sql, err)
+			continue
+		}
+		if got := queryRows(res); got != tt.want {
+			t.Errorf("%s:\nexpected %s\ngot      %s", tt.sql, tt.want, got)
+		}
+	}
+}
+
+func TestQueryTablesColumns(t *testing.T) {
+	sales := NewDataTable("Sales")
+	sales.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "RegionID", Type: reflect.TypeOf(0)},
+		{Name: "Amount", Type: reflect.TypeOf([]byte{}), DBType: "DECIMAL"},
+	})
+	regions := NewDataTable("Regions")
+	regions.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0))},
+		{Name: "Region", Type: reflect.TypeOf(""), Length: 20},
+	})
+	r := sales.NewRow()
+	r.Cells[0].Value = 1
+	r.Cells[1].Value = 1
+	r.Cells[2].Value = []byte("10.50")
+	sales.AddRow(&r)
+	r = regions.NewRow()
+	r.Cells[0].Value = int64(1)
+	r.Cells[1].Value = "North"
+	regions.AddRow(&r)
+	tables := map[string]*DataTable{"sales": sales, "regions": regions}
+
+	res, err := QueryTables("SELECT r.ID, s.ID, Region AS Name, SUM(Amount) FROM regions r JOIN sales s ON r.ID = s.RegionID GROUP BY r.ID, s.ID, Region", tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, c := range res.Columns {
+		names = append(names, c.Name)
+	}
+	if fmt.Sprint(names) != "[ID ID1 Name SUM(Amount)]" {
+		t.Fatalf("unexpected column names %v", names)
+	}
+	if res.Columns[0].Type != reflect.TypeOf(int64(0)) || res.Columns[2].Length != 20 || res.Columns[3].Type != reflect.TypeOf(0.0) {
+		t.Fatalf("unexpected column types %v", res.Columns)
+	}
+}
+
+func TestQueryTablesErrors(t *testing.T) {
+	sales := NewDataTable("Sales")
+	sales.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "RegionID", Type: reflect.TypeOf(0)},
+		{Name: "Amount", Type: reflect.TypeOf(0.0)},
+	})
+	for i, region := range []int{1, 1, 2, 3, 2, 9} {
+		r := sales.NewRow()
+		r.Cells[0].Value = i + 1
+		r.Cells[1].Value = region
+		r.Cells[2].Value = 1.0
+		sales.AddRow(&r)
+	}
+	regions := NewDataTable("Regions")
+	regions.AddColumn("ID", reflect.TypeOf(int64(0)), 0, "")
+	tables := map[string]*DataTable{"sales": sales, "regions": regions}
+
+	for _, sql := range []string{
+		"SELECT FROM sales",
+		"SELECT * FROM nope",
+		"SELECT ID FROM sales s JOIN regions r ON s.RegionID = r.ID",
+		"SELECT Nope FROM sales",
+		"SELECT ID FROM sales WHERE SUM(Amount) > 1",
+		"SELECT SUM(MAX(ID)) FROM sales",
+		"SELECT ID FROM sales RIGHT JOIN regions ON 1 = 1",
+		"SELECT FOO(ID) FROM sales",
+		"SELECT ID / 0 FROM sales",
+		"SELECT 'unterminated FROM sales",
+		"SELECT ID FROM sales GROUP BY RegionID",
+		"SELECT ID, COUNT(*) FROM sales",
+		"SELECT RegionID FROM sales GROUP BY RegionID HAVING MAX(ID) > ID",
+		"SELECT RegionID FROM sales GROUP BY 2",
+	} {
+		if _, err := QueryTables(sql, tables); err == nil {
+			t.Errorf("%s: expected an error", sql)
+		}
+	}
+
+	if _, err := QueryTables("SELECT ID FROM sales ORDER BY 5", tables); err == nil || err.Error() != "ORDER BY position 5 is out of range" {
+		t.Errorf("unexpected error %v", err)
+	}
+	res, err := QueryTables("SELECT RegionID * 2, COUNT(*) FROM sales s GROUP BY s.RegionID * 2 ORDER BY 1 DESC", tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := queryRows(res); got != "[[18 1] [6 1] [4 2] [2 2]]" {
+		t.Errorf("unexpected rows %s", got)
+	}
+}
+
+func TestQueryTablesJoinKeyTypes(t *testing.T) {
+	codes := NewDataTable("Codes")
+	codes.AddColumns([]Column{
+		{Name: "K", Type: reflect.TypeOf((*interface{})(nil)).Elem()},
+		{Name: "Name", Type: reflect.TypeOf("")},
+	})
+	for _, v := range [][2]interface{}{{"1", "text"}, {[]byte("2"), "bytes"}, {3.0, "float"}, {int8(0), "zero"}, {nil, "null"}} {
+		r := codes.NewRow()
+		r.Cells[0].Value = v[0]
+		r.Cells[1].Value = v[1]
+		codes.AddRow(&r)
+	}
+	ids := NewDataTable("IDs")
+	ids.AddColumns([]Column{{Name: "ID", Type: reflect.TypeOf(0)}})
+	for _, id := range []int{0, 1, 2, 3, 4} {
+		r := ids.NewRow()
+		r.Cells[0].Value = id
+		ids.AddRow(&r)
+	}
+	tables := map[string]*DataTable{"codes": codes, "ids": ids}
+
+	// = compares text and numbers as text, so the join finds the same rows as a filter of every pair
+	for _, sql := range []string{
+		"SELECT b.ID, a.Name FROM codes a JOIN ids b ON a.K = b.ID ORDER BY b.ID",
+		"SELECT b.ID, a.Name FROM ids b JOIN codes a ON a.K = b.ID ORDER BY b.ID",
+		"SELECT b.ID, a.Name FROM codes a CROSS JOIN ids b WHERE a.K = b.ID ORDER BY b.ID",
+	} {
+		res, err := QueryTables(sql, tables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := queryRows(res); got != "[[0 zero] [1 text] [2 bytes] [3 float]]" {
+			t.Errorf("%s: unexpected rows %s", sql, got)
+		}
+	}
+}
+
+func TestQueryTablesWholeFloats(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumns([]Column{
+		{Name: "Region", Type: reflect.TypeOf("")},
+		{Name: "Amt", Type: reflect.TypeOf(0.0)},
+	})
+	for _, amt := range []float64{3, 2.5} {
+		r := dt.NewRow()
+		r.Cells[0].Value = "North"
+		r.Cells[1].Value = amt
+		dt.AddRow(&r)
+	}
+
+	res, err := QueryTables("SELECT Region, Amt / 2 AS half, SUM(Amt) AS total, ROUND(Amt) AS r FROM orders GROUP BY Region, Amt ORDER BY Amt DESC", map[string]*DataTable{"orders": dt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := queryRows(res); got != "[[North 1.5 3 3] [North 1.25 2.5 3]]" {
+		t.Fatalf("unexpected rows %s", got)
+	}
+	for _, col := range res.Columns[1:] {
+		if col.Type != reflect.TypeOf(0.0) {
+			t.Errorf("expected float64 column %s, got %v", col.Name, col.Type)
+		}
+	}
+	for _, r := range res.Rows {
+		for _, c := range r.Cells[1:] {
+			if _, ok := c.Value.(float64); !ok {
+				t.Errorf("expected a float64 value, got %T", c.Value)
+			}
+		}
+	}
+
+	res, err = QueryTables("SELECT CASE WHEN Amt > 2.5 THEN 1 ELSE Amt END AS v FROM orders", map[string]*DataTable{"orders": dt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Columns[0].Type != reflect.TypeOf(0.0) || res.Rows[0].Cells[0].Value != 1.0 {
+		t.Fatalf("expected a column widened to float64, got %v %v", res.Columns[0].Type, res.Rows[0].Cells)
+	}
+}
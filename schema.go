@@ -0,0 +1,175 @@
+package datatable
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// LoadSchema - returns an empty table with the columns of a database table. Column names, types,
+// lengths and nullability come from a query that returns no rows. Primary key columns are looked up
+// in the catalog of the dialect. The generic dialect has no catalog, so no keys are marked
+func LoadSchema(ctx context.Context, db *sql.DB, tableName string, dialect Dialect) (*DataTable, error) {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+dialect.QuoteIdent(tableName)+" WHERE 1 = 0")
+	if err != nil {
+		return nil, err
+	}
+	colt, err := rows.ColumnTypes()
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := make([]Column, len(colt))
+	for i, ct := range colt {
+		cols[i] = Column{
+			Name:   ct.Name(),
+			DBType: strings.ToUpper(ct.DatabaseTypeName()),
+		}
+		cols[i].Type = columnGoType(ct.ScanType(), cols[i].DBType)
+		if l, ok := ct.Length(); ok && l > 0 {
+			cols[i].Length = l
+		}
+		if nullable, ok := ct.Nullable(); ok {
+			cols[i].NotNull = !nullable
+		}
+	}
+
+	_, name := splitTableName(tableName)
+	dt := NewDataTable(name)
+	dt.AddColumns(cols)
+
+	keys, err := loadPrimaryKey(ctx, db, tableName, dialect)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		if i := dt.columnIndex(k); i != -1 {
+			dt.Columns[i].PrimaryKey = true
+		}
+	}
+	return dt, nil
+}
+
+// loadPrimaryKey - returns the primary key columns of a table from the catalog of the dialect. A schema
+// qualified name such as dbo.Orders is looked up in that schema and other names in the current schema
+func loadPrimaryKey(ctx context.Context, db *sql.DB, tableName string, dialect Dialect) ([]string, error) {
+	query, args := dialect.primaryKeySQL(tableName)
+	if query == "" {
+		return nil, nil
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// primaryKeySQL - returns a query for the primary key columns of a table in key order, and its arguments.
+// Returns an empty query for dialects without a catalog
+func (d Dialect) primaryKeySQL(tableName string) (string, []interface{}) {
+	schema, name := splitTableName(tableName)
+
+	switch d {
+	case DialectSQLite:
+		if schema != "" {
+			return "SELECT name FROM pragma_table_info(?, ?) WHERE pk > 0 ORDER BY pk", []interface{}{name, schema}
+		}
+		return "SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk", []interface{}{name}
+	case DialectMySQL, DialectPostgres, DialectSQLServer:
+	default:
+		return "", nil
+	}
+
+	query := "SELECT kcu.COLUMN_NAME FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc " +
+		"JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu ON kcu.CONSTRAINT_NAME = tc.CONSTRAINT_NAME " +
+		"AND kcu.TABLE_SCHEMA = tc.TABLE_SCHEMA AND kcu.TABLE_NAME = tc.TABLE_NAME " +
+		"WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY' AND tc.TABLE_NAME = " + d.Placeholder(1)
+	args := []interface{}{name}
+	switch {
+	case schema != "":
+		query += " AND tc.TABLE_SCHEMA = " + d.Placeholder(2)
+		args = append(args, schema)
+	case d == DialectPostgres:
+		query += " AND tc.TABLE_SCHEMA = current_schema()"
+	case d == DialectMySQL:
+		query += " AND tc.TABLE_SCHEMA = DATABASE()"
+	case d == DialectSQLServer:
+		query += " AND tc.TABLE_SCHEMA = SCHEMA_NAME()"
+	}
+	return query + " ORDER BY kcu.ORDINAL_POSITION", args
+}
+
+// splitTableName - splits a schema qualified table name such as dbo.Orders at the last dot. The schema is
+// empty for unqualified names
+func splitTableName(name string) (string, string) {
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// columnGoType - returns the Go type of a column from the scan type of the driver. Nullable wrappers
+// are unwrapped. When the driver reports no useful type, the type is guessed from the database type name
+func columnGoType(scan reflect.Type, dbType string) reflect.Type {
+	if scan != nil {
+		switch scan {
+		case reflect.TypeOf(sql.NullString{}):
+			return reflect.TypeOf("")
+		case reflect.TypeOf(sql.NullInt64{}):
+			return reflect.TypeOf(int64(0))
+		case reflect.TypeOf(sql.NullInt32{}):
+			return reflect.TypeOf(int32(0))
+		case reflect.TypeOf(sql.NullInt16{}):
+			return reflect.TypeOf(int16(0))
+		case reflect.TypeOf(sql.NullByte{}):
+			return reflect.TypeOf(uint8(0))
+		case reflect.TypeOf(sql.NullFloat64{}):
+			return reflect.TypeOf(0.0)
+		case reflect.TypeOf(sql.NullBool{}):
+			return reflect.TypeOf(false)
+		case reflect.TypeOf(sql.NullTime{}):
+			return reflect.TypeOf(time.Time{})
+		case reflect.TypeOf(sql.RawBytes{}):
+			// Drivers that return raw text for every type
+		default:
+			if scan.Kind() == reflect.Ptr {
+				scan = scan.Elem()
+			}
+			if scan.Kind() != reflect.Interface {
+				return scan
+			}
+		}
+	}
+
+	t := dbType
+	if i := strings.IndexByte(t, '('); i != -1 {
+		t = t[:i]
+	}
+	switch strings.TrimSpace(t) {
+	case "TINYINT", "SMALLINT", "INT", "INTEGER", "BIGINT", "MEDIUMINT", "INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL":
+		return reflect.TypeOf(int64(0))
+	case "DECIMAL", "NUMERIC", "FLOAT", "REAL", "DOUBLE", "DOUBLE PRECISION", "MONEY", "SMALLMONEY", "FLOAT4", "FLOAT8":
+		return reflect.TypeOf(0.0)
+	case "BIT", "BOOL", "BOOLEAN":
+		return reflect.TypeOf(false)
+	case "DATE", "TIME", "DATETIME", "DATETIME2", "SMALLDATETIME", "DATETIMEOFFSET", "TIMESTAMP", "TIMESTAMPTZ":
+		return reflect.TypeOf(time.Time{})
+	case "BINARY", "VARBINARY", "IMAGE", "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA":
+		return reflect.TypeOf([]byte{})
+	}
+	return reflect.TypeOf("")
+}
@@ -0,0 +1,99 @@
+package datatable
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eaglebush/datatable/datatabletest"
+)
+
+func TestLoadSchema(t *testing.T) {
+	no, yes := false, true
+	db := datatabletest.Open(
+		datatabletest.Script{Columns: []datatabletest.Column{
+			{Name: "OrderID", DBType: "int", ScanType: reflect.TypeOf(int32(0)), Nullable: &no},
+			{Name: "Customer", DBType: "NVARCHAR", ScanType: reflect.TypeOf(sql.NullString{}), Length: 40, Nullable: &yes},
+			{Name: "Amount", DBType: "DECIMAL"},
+			{Name: "Created", DBType: "DATETIME2"},
+		}},
+		datatabletest.Script{
+			Columns: []datatabletest.Column{{Name: "COLUMN_NAME"}},
+			Rows:    [][]driver.Value{{"OrderID"}},
+		},
+	)
+	defer db.Close()
+
+	dt, err := LoadSchema(context.Background(), db, "dbo.Orders", DialectSQLServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dt.Name != "Orders" || len(dt.Rows) != 0 || dt.ColumnCount != 4 {
+		t.Fatalf("unexpected table %s with %d rows and %d columns", dt.Name, len(dt.Rows), dt.ColumnCount)
+	}
+
+	want := []Column{
+		{Name: "OrderID", Type: reflect.TypeOf(int32(0)), DBType: "INT", PrimaryKey: true, NotNull: true},
+		{Name: "Customer", Type: reflect.TypeOf(""), DBType: "NVARCHAR", Length: 40},
+		{Name: "Amount", Type: reflect.TypeOf(0.0), DBType: "DECIMAL"},
+		{Name: "Created", Type: reflect.TypeOf(time.Time{}), DBType: "DATETIME2"},
+	}
+	if !reflect.DeepEqual(dt.Columns, want) {
+		t.Fatalf("unexpected columns\n%+v\nexpected\n%+v", dt.Columns, want)
+	}
+
+	r := dt.NewRow()
+	if len(r.Cells) != 4 || r.Cells[1].ColumnName != "Customer" {
+		t.Fatalf("unexpected new row %+v", r.Cells)
+	}
+}
+
+func TestLoadSchemaGeneric(t *testing.T) {
+	db := datatabletest.Open(datatabletest.Script{Columns: []datatabletest.Column{{Name: "id", DBType: "INTEGER"}}})
+	defer db.Close()
+
+	dt, err := LoadSchema(context.Background(), db, "items", DialectGeneric)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dt.Columns) != 1 || dt.Columns[0].PrimaryKey || dt.Columns[0].Type != reflect.TypeOf(int64(0)) {
+		t.Fatalf("unexpected columns %+v", dt.Columns)
+	}
+
+	if _, err := LoadSchema(context.Background(), db, "missing", DialectGeneric); err == nil {
+		t.Fatal("expected an error when the query fails")
+	}
+}
+
+func TestPrimaryKeySQL(t *testing.T) {
+	const base = "SELECT kcu.COLUMN_NAME FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc " +
+		"JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu ON kcu.CONSTRAINT_NAME = tc.CONSTRAINT_NAME " +
+		"AND kcu.TABLE_SCHEMA = tc.TABLE_SCHEMA AND kcu.TABLE_NAME = tc.TABLE_NAME " +
+		"WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY' AND tc.TABLE_NAME = "
+	const order = " ORDER BY kcu.ORDINAL_POSITION"
+
+	tests := []struct {
+		dialect Dialect
+		name    string
+		want    string
+		args    []interface{}
+	}{
+		{DialectSQLServer, "Orders", base + "@p1 AND tc.TABLE_SCHEMA = SCHEMA_NAME()" + order, []interface{}{"Orders"}},
+		{DialectSQLServer, "dbo.Orders", base + "@p1 AND tc.TABLE_SCHEMA = @p2" + order, []interface{}{"Orders", "dbo"}},
+		{DialectPostgres, "Orders", base + "$1 AND tc.TABLE_SCHEMA = current_schema()" + order, []interface{}{"Orders"}},
+		{DialectPostgres, "sales.Orders", base + "$1 AND tc.TABLE_SCHEMA = $2" + order, []interface{}{"Orders", "sales"}},
+		{DialectMySQL, "Orders", base + "? AND tc.TABLE_SCHEMA = DATABASE()" + order, []interface{}{"Orders"}},
+		{DialectSQLite, "Orders", "SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk", []interface{}{"Orders"}},
+		{DialectSQLite, "aux.Orders", "SELECT name FROM pragma_table_info(?, ?) WHERE pk > 0 ORDER BY pk", []interface{}{"Orders", "aux"}},
+		{DialectGeneric, "Orders", "", nil},
+	}
+	for _, tc := range tests {
+		got, args := tc.dialect.primaryKeySQL(tc.name)
+		if got != tc.want || !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("dialect %d, %s: got %s %v", tc.dialect, tc.name, got, args)
+		}
+	}
+}
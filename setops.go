@@ -0,0 +1,206 @@
+package datatable
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ColumnMatch - how set operations pair the columns of two tables
+type ColumnMatch int
+
+const (
+	MatchByName     ColumnMatch = iota // pair columns by name, ignoring case
+	MatchByPosition                    // pair columns by position
+)
+
+// UnionAll - returns a new table with the rows of the table followed by the rows of other. Columns are paired
+// by name or position and must have compatible types. The result has the column names and order of the table.
+// Where paired types differ, integer and float columns become float64, integer columns of different sizes
+// become int64 and string and []byte columns become string, and the values of such columns are converted
+// to the result type
+func (dt *DataTable) UnionAll(other *DataTable, match ColumnMatch) (*DataTable, error) {
+	res, pairs, convert, err := dt.setSchema(other, match)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(dt.Rows)+len(other.Rows))
+	for i := range dt.Rows {
+		rows = append(rows, res.setRow(&dt.Rows[i], nil, convert))
+	}
+	for i := range other.Rows {
+		rows = append(rows, res.setRow(&other.Rows[i], pairs, convert))
+	}
+	res.AddRows(rows)
+	return res, nil
+}
+
+// Union - returns a new table with the distinct rows found in the table or in other, in the order they first
+// appear. Columns are paired as in UnionAll and NULL values are equal to each other
+func (dt *DataTable) Union(other *DataTable, match ColumnMatch) (*DataTable, error) {
+	res, err := dt.UnionAll(other, match)
+	if err != nil {
+		return nil, err
+	}
+	res.DropDuplicates(KeepFirst)
+	return res, nil
+}
+
+// Intersect - returns a new table with the distinct rows of the table that are also found in other.
+// Columns are paired as in UnionAll and NULL values are equal to each other
+func (dt *DataTable) Intersect(other *DataTable, match ColumnMatch) (*DataTable, error) {
+	return dt.setFilter(other, match, true)
+}
+
+// Except - returns a new table with the distinct rows of the table that are not found in other.
+// Columns are paired as in UnionAll and NULL values are equal to each other
+func (dt *DataTable) Except(other *DataTable, match ColumnMatch) (*DataTable, error) {
+	return dt.setFilter(other, match, false)
+}
+
+// setFilter - returns the distinct rows of the table that are found, or not found, in other
+func (dt *DataTable) setFilter(other *DataTable, match ColumnMatch, found bool) (*DataTable, error) {
+	res, pairs, convert, err := dt.setSchema(other, match)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, _ := res.distinctColumns(nil)
+	key := res.distinctKey(idx)
+	in := make(map[string]bool, len(other.Rows))
+	for i := range other.Rows {
+		r := res.setRow(&other.Rows[i], pairs, convert)
+		in[key(&r)] = true
+	}
+
+	seen := make(map[string]bool)
+	var rows []Row
+	for i := range dt.Rows {
+		r := res.setRow(&dt.Rows[i], nil, convert)
+		k := key(&r)
+		if seen[k] || in[k] != found {
+			continue
+		}
+		seen[k] = true
+		rows = append(rows, r)
+	}
+	res.AddRows(rows)
+	return res, nil
+}
+
+// setSchema - returns an empty result table for a set operation, the index of the column of other paired
+// with each column of the table and whether the values of each column are converted to its type
+func (dt *DataTable) setSchema(other *DataTable, match ColumnMatch) (*DataTable, []int, []bool, error) {
+	if other == nil {
+		return nil, nil, nil, errors.New("the other table is nil")
+	}
+	if len(dt.Columns) != len(other.Columns) {
+		return nil, nil, nil, fmt.Errorf("tables have %d and %d columns", len(dt.Columns), len(other.Columns))
+	}
+
+	pairs := make([]int, len(dt.Columns))
+	convert := make([]bool, len(dt.Columns))
+	res := NewDataTable(dt.Name)
+	for i, col := range dt.Columns {
+		pairs[i] = i
+		if match == MatchByName {
+			if pairs[i] = other.columnIndex(col.Name); pairs[i] == -1 {
+				return nil, nil, nil, fmt.Errorf("column %s does not exist in %s", col.Name, other.Name)
+			}
+		}
+		oc := other.Columns[pairs[i]]
+
+		t, ok := setColumnType(col.Type, oc.Type)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("column %s has type %v and column %s has type %v", col.Name, col.Type, oc.Name, oc.Type)
+		}
+		convert[i] = col.Type != oc.Type && col.Type != nil && oc.Type != nil
+		col.Type = t
+		col.PrimaryKey = false
+		col.NotNull = col.NotNull && oc.NotNull
+		col.Length = max(col.Length, oc.Length)
+		res.AddColumns([]Column{col})
+	}
+	return res, pairs, convert, nil
+}
+
+// setColumnType - returns the result type of a pair of column types and false if they are incompatible
+func setColumnType(a, b reflect.Type) (reflect.Type, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == nil:
+		return b, true
+	case b == nil:
+		return a, true
+	}
+
+	bytesType := reflect.TypeOf([]byte{})
+	ka, kb := setTypeKind(a), setTypeKind(b)
+	switch {
+	case ka == reflect.String && (kb == reflect.String || b == bytesType),
+		kb == reflect.String && a == bytesType:
+		return reflect.TypeOf(""), true
+	case ka == reflect.Int64 && kb == reflect.Int64:
+		return reflect.TypeOf(int64(0)), true
+	case (ka == reflect.Int64 || ka == reflect.Float64) && (kb == reflect.Int64 || kb == reflect.Float64):
+		return reflect.TypeOf(0.0), true
+	}
+	return nil, false
+}
+
+// setTypeKind - groups a type as Int64 for integers, Float64 for floats or String for strings
+func setTypeKind(t reflect.Type) reflect.Kind {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.Int64
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.String:
+		return reflect.String
+	}
+	return t.Kind()
+}
+
+// setRow - returns a new row of the table with the values of src, taking column i from cell pairs[i] of src,
+// or cell i if pairs is nil, and converting the values of the columns marked in convert to the column types
+func (dt *DataTable) setRow(src *Row, pairs []int, convert []bool) Row {
+	r := dt.NewRow()
+	for i, col := range dt.Columns {
+		c := i
+		if pairs != nil {
+			c = pairs[i]
+		}
+		if c >= len(src.Cells) {
+			continue
+		}
+		v := copyValue(src.Cells[c].Value)
+		if convert[i] {
+			v = convertSetValue(v, col.Type)
+		}
+		r.Cells[i].Value = v
+	}
+	return r
+}
+
+// convertSetValue - converts a numeric or []byte value to a numeric or string column type. Other values are
+// returned as they are
+func convertSetValue(v interface{}, t reflect.Type) interface{} {
+	if v == nil || t == nil {
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type() == t {
+		return v
+	}
+
+	switch kv, kt := setTypeKind(rv.Type()), setTypeKind(t); {
+	case kt == reflect.String && rv.Type() == reflect.TypeOf([]byte{}):
+		return rv.Convert(t).Interface()
+	case (kv == reflect.Int64 || kv == reflect.Float64) && (kt == reflect.Int64 || kt == reflect.Float64):
+		return rv.Convert(t).Interface()
+	}
+	return v
+}
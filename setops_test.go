@@ -0,0 +1,172 @@
+package datatable
+
+import (
+	"reflect"
+	"testing"
+)
+
+// checkSetRows - compares the values of a result table
+func checkSetRows(t *testing.T, dt *DataTable, want ...[]interface{}) {
+	t.Helper()
+	if len(dt.Rows) != len(want) {
+		t.Fatalf("got %d rows, expected %d", len(dt.Rows), len(want))
+	}
+	for i, w := range want {
+		for j, v := range w {
+			if got := dt.Rows[i].Cells[j].Value; !reflect.DeepEqual(got, v) {
+				t.Errorf("row %d column %d: got %#v, expected %#v", i, j, got, v)
+			}
+		}
+		if dt.Rows[i].Cells[0].RowIndex != i {
+			t.Errorf("row %d has RowIndex %d", i, dt.Rows[i].Cells[0].RowIndex)
+		}
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := NewDataTable("A")
+	a.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0), PrimaryKey: true},
+		{Name: "Name", Type: reflect.TypeOf(""), Length: 10},
+	})
+	b := NewDataTable("B")
+	b.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0)), PrimaryKey: true},
+		{Name: "Name", Type: reflect.TypeOf(""), Length: 10},
+	})
+	for _, v := range []struct {
+		dt       *DataTable
+		id, name interface{}
+	}{
+		{a, 1, "one"}, {a, 2, "two"}, {a, 2, "two"}, {a, 3, nil},
+		{b, int64(2), []byte("two")}, {b, int64(3), nil}, {b, int64(4), "four"},
+	} {
+		r := v.dt.NewRow()
+		r.Cells[0].Value, r.Cells[1].Value = v.id, v.name
+		v.dt.AddRow(&r)
+	}
+
+	all, err := a.UnionAll(b, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Columns[0].Type != reflect.TypeOf(int64(0)) || all.Columns[0].PrimaryKey || all.Name != "A" {
+		t.Errorf("unexpected result column %+v", all.Columns[0])
+	}
+	checkSetRows(t, all,
+		[]interface{}{int64(1), "one"}, []interface{}{int64(2), "two"}, []interface{}{int64(2), "two"},
+		[]interface{}{int64(3), nil}, []interface{}{int64(2), []byte("two")}, []interface{}{int64(3), nil},
+		[]interface{}{int64(4), "four"})
+
+	u, err := a.Union(b, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSetRows(t, u, []interface{}{int64(1), "one"}, []interface{}{int64(2), "two"}, []interface{}{int64(3), nil},
+		[]interface{}{int64(4), "four"})
+
+	in, err := a.Intersect(b, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSetRows(t, in, []interface{}{int64(2), "two"}, []interface{}{int64(3), nil})
+
+	ex, err := a.Except(b, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSetRows(t, ex, []interface{}{int64(1), "one"})
+
+	ex, err = b.Except(a, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSetRows(t, ex, []interface{}{int64(4), "four"})
+}
+
+func TestSetOperationsMatching(t *testing.T) {
+	a := NewDataTable("A")
+	a.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0), PrimaryKey: true},
+		{Name: "Name", Type: reflect.TypeOf(""), Length: 10},
+	})
+	r := a.NewRow()
+	r.Cells[0].Value, r.Cells[1].Value = 1, "one"
+	a.AddRow(&r)
+
+	// Columns in a different order pair by name but not by position
+	b := NewDataTable("B")
+	b.AddColumns([]Column{{Name: "name", Type: reflect.TypeOf("")}, {Name: "id", Type: reflect.TypeOf(0.0)}})
+	r = b.NewRow()
+	r.Cells[0].Value, r.Cells[1].Value = "one", 1.0
+	b.AddRow(&r)
+
+	in, err := a.Intersect(b, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Columns[0].Type != reflect.TypeOf(0.0) {
+		t.Errorf("expected a float64 ID, got %v", in.Columns[0].Type)
+	}
+	checkSetRows(t, in, []interface{}{1.0, "one"})
+
+	if _, err := a.Union(b, MatchByPosition); err == nil {
+		t.Error("expected a type error when pairing by position")
+	}
+
+	c := NewDataTable("C")
+	c.AddColumns([]Column{{Name: "Code", Type: reflect.TypeOf(0)}, {Name: "Label", Type: reflect.TypeOf([]byte{})}})
+	r = c.NewRow()
+	r.Cells[0].Value, r.Cells[1].Value = 1, []byte("one")
+	c.AddRow(&r)
+
+	in, err = a.Intersect(c, MatchByPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSetRows(t, in, []interface{}{1, "one"})
+	if _, err := a.Union(c, MatchByName); err == nil {
+		t.Error("expected a missing column error when pairing by name")
+	}
+
+	d := NewDataTable("D")
+	d.AddColumns([]Column{{Name: "ID", Type: reflect.TypeOf(0)}, {Name: "Name", Type: reflect.TypeOf("")}, {Name: "Extra", Type: reflect.TypeOf("")}})
+	if _, err := a.UnionAll(d, MatchByPosition); err == nil {
+		t.Error("expected a column count error")
+	}
+	if _, err := a.Except(nil, MatchByName); err == nil {
+		t.Error("expected an error for a nil table")
+	}
+}
+
+func TestSetOperationsBinary(t *testing.T) {
+	a, b := NewDataTable("A"), NewDataTable("B")
+	for _, dt := range []*DataTable{a, b} {
+		dt.AddColumns([]Column{
+			{Name: "B", Type: reflect.TypeOf([]byte{})},
+			{Name: "S", Type: reflect.TypeOf("")},
+		})
+	}
+
+	// The rows differ only in where the NUL bytes split the values
+	for _, v := range []struct {
+		dt   *DataTable
+		b, s string
+	}{{a, "x\x00string:y", "z"}, {a, "\x00", "\x00"}, {b, "x", "y\x00string:z"}, {b, "\x00", "\x00"}} {
+		r := v.dt.NewRow()
+		r.Cells[0].Value, r.Cells[1].Value = []byte(v.b), v.s
+		v.dt.AddRow(&r)
+	}
+
+	in, err := a.Intersect(b, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSetRows(t, in, []interface{}{[]byte("\x00"), "\x00"})
+
+	ex, err := a.Except(b, MatchByName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSetRows(t, ex, []interface{}{[]byte("x\x00string:y"), "z"})
+}
@@ -0,0 +1,547 @@
+package datatable
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Snapshot format. A header of the magic, the version and flags is followed by frames of a kind byte,
+// the uncompressed and stored lengths, the stored bytes and the CRC-32C of the uncompressed bytes.
+// The first frame holds the schema, row frames hold blocks of rows and an end frame holds the row count
+const (
+	snapshotMagic   = "DTSNAP"
+	snapshotVersion = 1
+
+	snapshotFlagDeflate = 1
+
+	snapshotFrameEnd    = 0
+	snapshotFrameSchema = 1
+	snapshotFrameRows   = 2
+
+	defaultSnapshotBlockRows = 4096
+	maxSnapshotFrame         = 1 << 30
+)
+
+// Type tags of column types and cell values. Column types of pointers have snapshotPointer set.
+// snapshotAny is only a column type, the values of such columns have their own tags
+const (
+	snapshotNull byte = iota
+	snapshotBool
+	snapshotInt
+	snapshotInt8
+	snapshotInt16
+	snapshotInt32
+	snapshotInt64
+	snapshotUint
+	snapshotUint8
+	snapshotUint16
+	snapshotUint32
+	snapshotUint64
+	snapshotFloat32
+	snapshotFloat64
+	snapshotString
+	snapshotBytes
+	snapshotTime
+	snapshotAny
+
+	snapshotPointer byte = 0x80
+)
+
+// Time zone kinds of time values
+const (
+	snapshotUTC   = 0
+	snapshotLocal = 1
+	snapshotZone  = 2
+)
+
+var snapshotTypes = []reflect.Type{
+	snapshotBool:    reflect.TypeOf(false),
+	snapshotInt:     reflect.TypeOf(int(0)),
+	snapshotInt8:    reflect.TypeOf(int8(0)),
+	snapshotInt16:   reflect.TypeOf(int16(0)),
+	snapshotInt32:   reflect.TypeOf(int32(0)),
+	snapshotInt64:   reflect.TypeOf(int64(0)),
+	snapshotUint:    reflect.TypeOf(uint(0)),
+	snapshotUint8:   reflect.TypeOf(uint8(0)),
+	snapshotUint16:  reflect.TypeOf(uint16(0)),
+	snapshotUint32:  reflect.TypeOf(uint32(0)),
+	snapshotUint64:  reflect.TypeOf(uint64(0)),
+	snapshotFloat32: reflect.TypeOf(float32(0)),
+	snapshotFloat64: reflect.TypeOf(float64(0)),
+	snapshotString:  reflect.TypeOf(""),
+	snapshotBytes:   reflect.TypeOf([]byte{}),
+	snapshotTime:    reflect.TypeOf(time.Time{}),
+	snapshotAny:     reflect.TypeOf((*interface{})(nil)).Elem(),
+}
+
+var snapshotCRC = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrSnapshotChecksum - returned by Load when a frame of a snapshot does not match its checksum
+var ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+
+// SnapshotOptions - options for SaveWith
+type SnapshotOptions struct {
+	Compress  bool // compress frames with DEFLATE
+	BlockRows int  // rows per frame. Defaults to 4096, which is also used for tables without columns
+}
+
+// Save - writes the table in the binary snapshot format without compression. Column types and the Go type
+// of every cell value are kept, so Load returns an equal table
+func (dt *DataTable) Save(w io.Writer) error {
+	return dt.SaveWith(w, SnapshotOptions{})
+}
+
+// SaveWith - writes the table in the binary snapshot format. Rows are written in blocks so that only one
+// block is held in memory besides the table. Cell values must be nil, bool, an integer or float type,
+// string, []byte or time.Time
+func (dt *DataTable) SaveWith(w io.Writer, opts SnapshotOptions) error {
+	block := opts.BlockRows
+	if block <= 0 || len(dt.Columns) == 0 {
+		block = defaultSnapshotBlockRows
+	}
+
+	sw := &snapshotWriter{w: bufio.NewWriter(w)}
+	var flags byte
+	if opts.Compress {
+		flags |= snapshotFlagDeflate
+		zw, err := flate.NewWriter(nil, flate.BestSpeed)
+		if err != nil {
+			return err
+		}
+		sw.zw = zw
+	}
+	sw.w.WriteString(snapshotMagic)
+	sw.w.Write([]byte{snapshotVersion, flags})
+
+	var buf []byte
+	buf = appendSnapshotString(buf, dt.Name)
+	buf = binary.AppendUvarint(buf, uint64(len(dt.Columns)))
+	for _, col := range dt.Columns {
+		tag, err := snapshotTypeTag(col.Type)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", col.Name, err)
+		}
+		buf = appendSnapshotString(buf, col.Name)
+		buf = append(buf, tag)
+		buf = appendSnapshotString(buf, col.DBType)
+		buf = binary.AppendVarint(buf, col.Length)
+		var f byte
+		if col.PrimaryKey {
+			f |= 1
+		}
+		if col.NotNull {
+			f |= 2
+		}
+		buf = append(buf, f)
+	}
+	if err := sw.frame(snapshotFrameSchema, buf); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(dt.Rows); start += block {
+		end := min(start+block, len(dt.Rows))
+		buf = binary.AppendUvarint(buf[:0], uint64(end-start))
+		for i := start; i < end; i++ {
+			cells := dt.Rows[i].Cells
+			for j := range dt.Columns {
+				// Raw values, as Row.Value returns []byte as string
+				var v interface{}
+				if j < len(cells) {
+					v = cells[j].Value
+				}
+				var err error
+				if buf, err = appendSnapshotValue(buf, v); err != nil {
+					return fmt.Errorf("row %d column %s: %w", i, dt.Columns[j].Name, err)
+				}
+			}
+		}
+		if err := sw.frame(snapshotFrameRows, buf); err != nil {
+			return err
+		}
+	}
+
+	if err := sw.frame(snapshotFrameEnd, binary.AppendUvarint(buf[:0], uint64(len(dt.Rows)))); err != nil {
+		return err
+	}
+	return sw.w.Flush()
+}
+
+// snapshotWriter - writes frames, compressing them if zw is set
+type snapshotWriter struct {
+	w   *bufio.Writer
+	zw  *flate.Writer
+	out bytes.Buffer
+}
+
+func (sw *snapshotWriter) frame(kind byte, data []byte) error {
+	stored := data
+	if sw.zw != nil {
+		sw.out.Reset()
+		sw.zw.Reset(&sw.out)
+		sw.zw.Write(data)
+		if err := sw.zw.Close(); err != nil {
+			return err
+		}
+		stored = sw.out.Bytes()
+	}
+
+	var hdr []byte
+	hdr = append(hdr, kind)
+	hdr = binary.AppendUvarint(hdr, uint64(len(data)))
+	hdr = binary.AppendUvarint(hdr, uint64(len(stored)))
+	sw.w.Write(hdr)
+	sw.w.Write(stored)
+	_, err := sw.w.Write(binary.LittleEndian.AppendUint32(nil, crc32.Checksum(data, snapshotCRC)))
+	return err
+}
+
+// snapshotTypeTag - returns the tag of a column type
+func snapshotTypeTag(t reflect.Type) (byte, error) {
+	if t == nil {
+		return snapshotNull, nil
+	}
+	var ptr byte
+	if t.Kind() == reflect.Ptr {
+		ptr, t = snapshotPointer, t.Elem()
+	}
+	for tag, st := range snapshotTypes {
+		if st == t {
+			return byte(tag) | ptr, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported column type %v", t)
+}
+
+func appendSnapshotString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// appendSnapshotValue - appends the tag and encoding of a cell value
+func appendSnapshotValue(b []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(b, snapshotNull), nil
+	case bool:
+		if x {
+			return append(b, snapshotBool, 1), nil
+		}
+		return append(b, snapshotBool, 0), nil
+	case int:
+		return binary.AppendVarint(append(b, snapshotInt), int64(x)), nil
+	case int8:
+		return binary.AppendVarint(append(b, snapshotInt8), int64(x)), nil
+	case int16:
+		return binary.AppendVarint(append(b, snapshotInt16), int64(x)), nil
+	case int32:
+		return binary.AppendVarint(append(b, snapshotInt32), int64(x)), nil
+	case int64:
+		return binary.AppendVarint(append(b, snapshotInt64), x), nil
+	case uint:
+		return binary.AppendUvarint(append(b, snapshotUint), uint64(x)), nil
+	case uint8:
+		return append(b, snapshotUint8, x), nil
+	case uint16:
+		return binary.AppendUvarint(append(b, snapshotUint16), uint64(x)), nil
+	case uint32:
+		return binary.AppendUvarint(append(b, snapshotUint32), uint64(x)), nil
+	case uint64:
+		return binary.AppendUvarint(append(b, snapshotUint64), x), nil
+	case float32:
+		return binary.LittleEndian.AppendUint32(append(b, snapshotFloat32), math.Float32bits(x)), nil
+	case float64:
+		return binary.LittleEndian.AppendUint64(append(b, snapshotFloat64), math.Float64bits(x)), nil
+	case string:
+		return appendSnapshotString(append(b, snapshotString), x), nil
+	case []byte:
+		b = binary.AppendUvarint(append(b, snapshotBytes), uint64(len(x)))
+		return append(b, x...), nil
+	case time.Time:
+		b = binary.AppendVarint(append(b, snapshotTime), x.Unix())
+		b = binary.AppendUvarint(b, uint64(x.Nanosecond()))
+		switch loc := x.Location(); loc {
+		case time.UTC:
+			return append(b, snapshotUTC), nil
+		case time.Local:
+			return append(b, snapshotLocal), nil
+		default:
+			_, offset := x.Zone()
+			b = appendSnapshotString(append(b, snapshotZone), loc.String())
+			return binary.AppendVarint(b, int64(offset)), nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+// Load - reads a table written by Save or SaveWith
+func Load(r io.Reader) (*DataTable, error) {
+	br := bufio.NewReader(r)
+	hdr := make([]byte, len(snapshotMagic)+2)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, fmt.Errorf("reading snapshot header: %w", err)
+	}
+	if string(hdr[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, errors.New("not a snapshot")
+	}
+	if v := hdr[len(snapshotMagic)]; v > snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", v)
+	}
+	sr := &snapshotReader{r: br, deflate: hdr[len(snapshotMagic)+1]&snapshotFlagDeflate != 0}
+
+	kind, data, err := sr.frame()
+	if err != nil {
+		return nil, err
+	}
+	if kind != snapshotFrameSchema {
+		return nil, errors.New("snapshot has no schema")
+	}
+	d := &snapshotDecoder{b: data}
+	dt := NewDataTable(d.string())
+	cols := make([]Column, d.uvarint())
+	for i := range cols {
+		cols[i].Name = d.string()
+		tag := d.byte()
+		if t := tag &^ snapshotPointer; t != snapshotNull {
+			if int(t) >= len(snapshotTypes) {
+				return nil, fmt.Errorf("column %s has an unknown type tag %d", cols[i].Name, tag)
+			}
+			cols[i].Type = snapshotTypes[t]
+			if tag&snapshotPointer != 0 {
+				cols[i].Type = reflect.PointerTo(cols[i].Type)
+			}
+		}
+		cols[i].DBType = d.string()
+		cols[i].Length = d.varint()
+		f := d.byte()
+		cols[i].PrimaryKey, cols[i].NotNull = f&1 != 0, f&2 != 0
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	dt.AddColumns(cols)
+
+	for {
+		kind, data, err := sr.frame()
+		if err != nil {
+			return nil, err
+		}
+		d := &snapshotDecoder{b: data}
+
+		switch kind {
+		case snapshotFrameRows:
+			// Every cell takes at least one byte, and frames of a table without columns hold at most a
+			// default block, so a corrupt count cannot allocate more rows than the frame holds
+			n := d.uvarint()
+			limit := uint64(defaultSnapshotBlockRows)
+			if len(cols) > 0 {
+				limit = uint64(len(d.b)) / uint64(len(cols))
+			}
+			if d.err != nil || n > limit {
+				return nil, errors.New("invalid snapshot row count")
+			}
+			rows := make([]Row, n)
+			for i := range rows {
+				rows[i] = dt.NewRow()
+				for j := range rows[i].Cells {
+					rows[i].Cells[j].Value = d.value()
+				}
+			}
+			if d.err != nil {
+				return nil, d.err
+			}
+			dt.AddRows(rows)
+		case snapshotFrameEnd:
+			if n := d.uvarint(); d.err != nil || n != uint64(len(dt.Rows)) {
+				return nil, errors.New("snapshot row count mismatch")
+			}
+			return dt, nil
+		default:
+			return nil, fmt.Errorf("unknown snapshot frame %d", kind)
+		}
+	}
+}
+
+// snapshotReader - reads and checks frames
+type snapshotReader struct {
+	r       *bufio.Reader
+	deflate bool
+	zr      io.ReadCloser
+}
+
+func (sr *snapshotReader) frame() (byte, []byte, error) {
+	kind, err := sr.r.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading snapshot frame: %w", noEOF(err))
+	}
+	size, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading snapshot frame: %w", noEOF(err))
+	}
+	stored, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return 0, nil, fmt.Errorf("reading snapshot frame: %w", noEOF(err))
+	}
+	if size > maxSnapshotFrame || stored > maxSnapshotFrame || (!sr.deflate && size != stored) {
+		return 0, nil, errors.New("invalid snapshot frame length")
+	}
+
+	buf := make([]byte, stored+4)
+	if _, err := io.ReadFull(sr.r, buf); err != nil {
+		return 0, nil, fmt.Errorf("reading snapshot frame: %w", noEOF(err))
+	}
+	data, sum := buf[:stored], binary.LittleEndian.Uint32(buf[stored:])
+
+	if sr.deflate {
+		if sr.zr == nil {
+			sr.zr = flate.NewReader(bytes.NewReader(data))
+		} else {
+			sr.zr.(flate.Resetter).Reset(bytes.NewReader(data), nil)
+		}
+		raw := make([]byte, size)
+		if _, err := io.ReadFull(sr.zr, raw); err != nil {
+			return 0, nil, fmt.Errorf("decompressing snapshot frame: %w", noEOF(err))
+		}
+		data = raw
+	}
+	if crc32.Checksum(data, snapshotCRC) != sum {
+		return 0, nil, ErrSnapshotChecksum
+	}
+	return kind, data, nil
+}
+
+// noEOF - reports an end of data inside a snapshot as unexpected
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// snapshotDecoder - decodes the data of a frame. The first error is kept and later reads return zero values
+type snapshotDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *snapshotDecoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("invalid snapshot data")
+	}
+	d.b = nil
+}
+
+func (d *snapshotDecoder) byte() byte {
+	if len(d.b) < 1 {
+		d.fail()
+		return 0
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c
+}
+
+func (d *snapshotDecoder) take(n uint64) []byte {
+	if n > uint64(len(d.b)) {
+		d.fail()
+		return nil
+	}
+	p := d.b[:n]
+	d.b = d.b[n:]
+	return p
+}
+
+func (d *snapshotDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *snapshotDecoder) varint() int64 {
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *snapshotDecoder) string() string {
+	return string(d.take(d.uvarint()))
+}
+
+// value - decodes a tagged cell value
+func (d *snapshotDecoder) value() interface{} {
+	switch tag := d.byte(); tag {
+	case snapshotNull:
+		return nil
+	case snapshotBool:
+		return d.byte() != 0
+	case snapshotInt:
+		return int(d.varint())
+	case snapshotInt8:
+		return int8(d.varint())
+	case snapshotInt16:
+		return int16(d.varint())
+	case snapshotInt32:
+		return int32(d.varint())
+	case snapshotInt64:
+		return d.varint()
+	case snapshotUint:
+		return uint(d.uvarint())
+	case snapshotUint8:
+		return d.byte()
+	case snapshotUint16:
+		return uint16(d.uvarint())
+	case snapshotUint32:
+		return uint32(d.uvarint())
+	case snapshotUint64:
+		return d.uvarint()
+	case snapshotFloat32:
+		if b := d.take(4); b != nil {
+			return math.Float32frombits(binary.LittleEndian.Uint32(b))
+		}
+	case snapshotFloat64:
+		if b := d.take(8); b != nil {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+	case snapshotString:
+		return d.string()
+	case snapshotBytes:
+		return bytes.Clone(d.take(d.uvarint()))
+	case snapshotTime:
+		t := time.Unix(d.varint(), int64(d.uvarint()))
+		switch d.byte() {
+		case snapshotUTC:
+			return t.UTC()
+		case snapshotLocal:
+			return t.Local()
+		case snapshotZone:
+			name, offset := d.string(), int(d.varint())
+			if loc, err := time.LoadLocation(name); err == nil {
+				if _, o := t.In(loc).Zone(); o == offset {
+					return t.In(loc)
+				}
+			}
+			return t.In(time.FixedZone(name, offset))
+		default:
+			d.fail()
+		}
+	default:
+		d.fail()
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package datatable
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	dt := NewDataTable("Cache")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0)), PrimaryKey: true, NotNull: true},
+		{Name: "Name", Type: reflect.TypeOf(""), DBType: "VARCHAR", Length: 50},
+		{Name: "Score", Type: reflect.TypeOf(float32(0))},
+		{Name: "Seen", Type: reflect.TypeOf(&time.Time{})},
+		{Name: "Raw", Type: reflect.TypeOf([]byte{})},
+		{Name: "Any"},
+	})
+
+	zone := time.FixedZone("UTC+8", 8*3600)
+	others := []interface{}{true, int8(-8), int16(300), int32(-70000), 42, uint(7), uint8(255), uint16(65535),
+		uint32(1 << 31), uint64(1 << 63), 2.5, nil}
+	for i := 0; i < 50; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = int64(i)
+		if i%3 != 0 {
+			r.Cells[1].Value = strings.Repeat("x", i%7)
+		}
+		r.Cells[2].Value = float32(i) / 4
+		switch i % 3 {
+		case 0:
+			r.Cells[3].Value = time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+		case 1:
+			r.Cells[3].Value = time.Date(1901, 1, 2, 3, 4, 5, 0, zone)
+		}
+		if i%2 == 0 {
+			r.Cells[4].Value = []byte{byte(i), 0, 1}
+		} else {
+			r.Cells[4].Value = []byte{}
+		}
+		r.Cells[5].Value = others[i%len(others)]
+		dt.AddRow(&r)
+	}
+
+	for _, opts := range []SnapshotOptions{{}, {Compress: true, BlockRows: 7}} {
+		var buf bytes.Buffer
+		if err := dt.SaveWith(&buf, opts); err != nil {
+			t.Fatal(err)
+		}
+		got, err := Load(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Name != dt.Name || !reflect.DeepEqual(got.Columns, dt.Columns) {
+			t.Fatalf("got schema %s %+v, expected %s %+v", got.Name, got.Columns, dt.Name, dt.Columns)
+		}
+		if len(got.Rows) != len(dt.Rows) || got.RowCount != dt.RowCount {
+			t.Fatalf("got %d rows, expected %d", len(got.Rows), len(dt.Rows))
+		}
+		for i := range dt.Rows {
+			for j := range dt.Columns {
+				g, w := got.Rows[i].Cells[j].Value, dt.Rows[i].Cells[j].Value
+				if gt, ok := g.(time.Time); ok {
+					wt := w.(time.Time)
+					_, gotOff := gt.Zone()
+					_, wantOff := wt.Zone()
+					if !gt.Equal(wt) || gotOff != wantOff {
+						t.Errorf("row %d column %d: got %v, expected %v", i, j, g, w)
+					}
+					continue
+				}
+				if !reflect.DeepEqual(g, w) {
+					t.Errorf("row %d column %d: got %#v, expected %#v", i, j, g, w)
+				}
+			}
+		}
+		if v := got.Rows[1].Value("name"); v != "x" {
+			t.Errorf("lookup by name returned %v", v)
+		}
+	}
+}
+
+func TestSnapshotInterfaceColumn(t *testing.T) {
+	dt := NewDataTable("Settings")
+	dt.AddColumns([]Column{{Name: "Value", Type: reflect.TypeOf((*interface{})(nil)).Elem()}})
+	for _, v := range []interface{}{"on", int64(3), 1.5, nil, []byte{0}} {
+		r := dt.NewRow()
+		r.Cells[0].Value = v
+		dt.AddRow(&r)
+	}
+
+	var buf bytes.Buffer
+	if err := dt.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Columns, dt.Columns) {
+		t.Fatalf("got columns %+v, expected %+v", got.Columns, dt.Columns)
+	}
+	for i := range dt.Rows {
+		if g, w := got.Rows[i].Cells[0].Value, dt.Rows[i].Cells[0].Value; !reflect.DeepEqual(g, w) {
+			t.Errorf("row %d: got %#v, expected %#v", i, g, w)
+		}
+	}
+}
+
+func TestSnapshotCompression(t *testing.T) {
+	dt := NewDataTable("Cache")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0))},
+		{Name: "Name", Type: reflect.TypeOf("")},
+	})
+	for i := 0; i < 2000; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = int64(i)
+		r.Cells[1].Value = strings.Repeat("x", i%7)
+		dt.AddRow(&r)
+	}
+
+	var plain, packed bytes.Buffer
+	if err := dt.Save(&plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := dt.SaveWith(&packed, SnapshotOptions{Compress: true}); err != nil {
+		t.Fatal(err)
+	}
+	if packed.Len() >= plain.Len() {
+		t.Errorf("compressed size %d is not less than %d", packed.Len(), plain.Len())
+	}
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	dt := NewDataTable("Cache")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int64(0))},
+		{Name: "Any"},
+	})
+	for i := 0; i < 10; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = int64(i)
+		dt.AddRow(&r)
+	}
+
+	var buf bytes.Buffer
+	if err := dt.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// Corrupt a byte of the last row frame
+	bad := bytes.Clone(data)
+	bad[len(bad)-12] ^= 0xff
+	if _, err := Load(bytes.NewReader(bad)); !errors.Is(err, ErrSnapshotChecksum) {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+
+	if _, err := Load(bytes.NewReader(data[:len(data)-3])); err == nil {
+		t.Error("expected an error for a truncated snapshot")
+	}
+
+	bad = bytes.Clone(data)
+	bad[len(snapshotMagic)] = snapshotVersion + 1
+	if _, err := Load(bytes.NewReader(bad)); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("expected a version error, got %v", err)
+	}
+
+	if _, err := Load(strings.NewReader("PAR1")); err == nil {
+		t.Error("expected an error for other data")
+	}
+
+	dt.Rows[2].Cells[1].Value = struct{}{}
+	if err := dt.Save(&bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "row 2 column Any") {
+		t.Errorf("expected an unsupported value error, got %v", err)
+	}
+
+	// Frames with valid checksums but row counts the frame cannot hold, with and without columns
+	for _, cols := range []int{0, 1} {
+		for _, n := range []uint64{1 << 63, math.MaxUint64, 5000} {
+			var crafted bytes.Buffer
+			sw := &snapshotWriter{w: bufio.NewWriter(&crafted)}
+			sw.w.WriteString(snapshotMagic)
+			sw.w.Write([]byte{snapshotVersion, 0})
+			schema := appendSnapshotString(nil, "Crafted")
+			schema = binary.AppendUvarint(schema, uint64(cols))
+			for range cols {
+				schema = append(appendSnapshotString(schema, "A"), snapshotInt64)
+				schema = append(binary.AppendVarint(appendSnapshotString(schema, ""), 0), 0)
+			}
+			sw.frame(snapshotFrameSchema, schema)
+			sw.frame(snapshotFrameRows, append(binary.AppendUvarint(nil, n), snapshotNull))
+			sw.frame(snapshotFrameEnd, binary.AppendUvarint(nil, n))
+			sw.w.Flush()
+			if _, err := Load(&crafted); err == nil || !strings.Contains(err.Error(), "row count") {
+				t.Errorf("%d columns, %d rows: expected a row count error, got %v", cols, n, err)
+			}
+		}
+	}
+
+	// Tables without columns are written in default blocks so that they load
+	dt = NewDataTable("Empty")
+	for range defaultSnapshotBlockRows + 1 {
+		dt.AddRow(&Row{})
+	}
+	buf.Reset()
+	if err := dt.SaveWith(&buf, SnapshotOptions{BlockRows: 1 << 20}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Load(&buf); err != nil || len(got.Rows) != defaultSnapshotBlockRows+1 {
+		t.Errorf("expected %d rows without columns, got %v", defaultSnapshotBlockRows+1, err)
+	}
+
+	dt = NewDataTable("Bad")
+	dt.AddColumns([]Column{{Name: "M", Type: reflect.TypeOf(map[string]int{})}})
+	if err := dt.Save(&bytes.Buffer{}); err == nil {
+		t.Error("expected an unsupported column type error")
+	}
+}
@@ -0,0 +1,76 @@
+package datatable
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sortColumn - a column index and direction to order rows by. Text values of numeric columns are
+// compared as numbers
+type sortColumn struct {
+	index   int
+	desc    bool
+	numeric bool
+}
+
+// parseSortColumns - resolves column names to sort columns. A leading "-" sorts the column descending
+// and a leading "+" ascending
+func (dt *DataTable) parseSortColumns(names []string) ([]sortColumn, error) {
+	cols := make([]sortColumn, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		desc := false
+		switch {
+		case strings.HasPrefix(name, "-"):
+			desc = true
+			name = name[1:]
+		case strings.HasPrefix(name, "+"):
+			name = name[1:]
+		}
+
+		idx := dt.columnIndex(name)
+		if idx == -1 {
+			return nil, fmt.Errorf("column %s does not exist", name)
+		}
+		cols = append(cols, sortColumn{index: idx, desc: desc, numeric: isNumericDBType(dt.Columns[idx].DBType)})
+	}
+	return cols, nil
+}
+
+// compareRows - orders two rows by the sort columns. Returns -1, 0 or 1
+func compareRows(a, b *Row, cols []sortColumn) int {
+	for _, c := range cols {
+		r := compareValues(sortValue(a, c), sortValue(b, c))
+		if r != 0 {
+			if c.desc {
+				return -r
+			}
+			return r
+		}
+	}
+	return 0
+}
+
+// sortValue - returns the value of a row to compare for a sort column. Drivers return DECIMAL and other
+// numeric columns as text, which is parsed when it holds a number
+func sortValue(row *Row, c sortColumn) interface{} {
+	v := cellValue(row, c.index)
+	if !c.numeric {
+		return v
+	}
+
+	var s string
+	switch t := v.(type) {
+	case []byte:
+		s = string(t)
+	case string:
+		s = t
+	default:
+		return v
+	}
+	if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+		return f
+	}
+	return v
+}
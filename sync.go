@@ -0,0 +1,99 @@
+package datatable
+
+import (
+	"maps"
+	"sync"
+)
+
+// SyncDataTable - a data table guarded by a read/write lock for use from many goroutines.
+// Readers go through Read and writers through Write or the row methods. A background refresh
+// can build a new table without holding the lock and then replace the current one with Swap.
+type SyncDataTable struct {
+	mu sync.RWMutex
+	dt *DataTable
+}
+
+// NewSyncDataTable - wraps a data table for concurrent use. A nil table is replaced with an empty one.
+// The caller should not use the table directly after wrapping it.
+func NewSyncDataTable(dt *DataTable) *SyncDataTable {
+	if dt == nil {
+		dt = NewDataTable("")
+	}
+	return &SyncDataTable{dt: dt}
+}
+
+// Read - runs fn while holding the read lock. The table and its rows must not be modified or retained by fn
+func (s *SyncDataTable) Read(fn func(dt *DataTable)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.dt)
+}
+
+// Write - runs fn while holding the write lock
+func (s *SyncDataTable) Write(fn func(dt *DataTable)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.dt)
+}
+
+// NewRow - returns a new row based on the column structure of the current table
+func (s *SyncDataTable) NewRow() Row {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dt.NewRow()
+}
+
+// AddRow - add a row to the current table
+func (s *SyncDataTable) AddRow(row *Row) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dt.AddRow(row)
+}
+
+// AddRows - adds a range of rows to the current table
+func (s *SyncDataTable) AddRows(rows []Row) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dt.AddRows(rows)
+}
+
+// RowCount - returns the number of rows of the current table
+func (s *SyncDataTable) RowCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dt.RowCount
+}
+
+// Row - returns a copy of the row at the index. The cells and the column name index are copied so the result
+// can be kept after the call
+func (s *SyncDataTable) Row(index int) (Row, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if index < 0 || index >= len(s.dt.Rows) {
+		return Row{}, false
+	}
+
+	src := s.dt.Rows[index]
+	r := Row{
+		Cells:                   make([]Cell, len(src.Cells)),
+		ColumnCount:             src.ColumnCount,
+		currentColumnNamesIndex: maps.Clone(src.currentColumnNamesIndex),
+	}
+	copy(r.Cells, src.Cells)
+	return r, true
+}
+
+// Swap - replaces the current table with dt and returns the previous one. Readers see either
+// the old or the new table, never a partially refreshed one. A nil table is replaced with an empty one.
+func (s *SyncDataTable) Swap(dt *DataTable) *DataTable {
+	if dt == nil {
+		dt = NewDataTable("")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.dt
+	s.dt = dt
+	return old
+}
@@ -0,0 +1,153 @@
+package datatable
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncDataTableConcurrentAccess(t *testing.T) {
+	dt := NewDataTable("Lookup")
+	dt.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	dt.AddColumn("Code", reflect.TypeOf(""), 12, "")
+	for i := 0; i < 10; i++ {
+		r := dt.NewRow()
+		r.Cells[0].Value = i
+		r.Cells[1].Value = "Code" + strconv.Itoa(i)
+		dt.AddRow(&r)
+	}
+	st := NewSyncDataTable(dt)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				st.Read(func(dt *DataTable) {
+					for j := range dt.Rows {
+						_ = dt.Rows[j].ValueString("Code")
+						_ = dt.Rows[j].Value("id")
+					}
+				})
+				if r, ok := st.Row(0); ok && r.ValueInt("ID") != 0 {
+					t.Errorf("unexpected ID %d", r.ValueInt("ID"))
+				}
+			}
+		}()
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			r := st.NewRow()
+			r.Cells[0].Value = 1000 + i
+			r.Cells[1].Value = "Added"
+			st.AddRow(&r)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			// A refresh holds the same first row
+			next := NewDataTable("Lookup")
+			next.AddColumn("ID", reflect.TypeOf(0), 0, "")
+			next.AddColumn("Code", reflect.TypeOf(""), 12, "")
+			r := next.NewRow()
+			r.Cells[0].Value = 0
+			r.Cells[1].Value = "Code0"
+			next.AddRow(&r)
+			st.Swap(next)
+		}
+	}()
+	wg.Wait()
+
+	st.Read(func(dt *DataTable) {
+		if dt.RowCount != len(dt.Rows) {
+			t.Errorf("RowCount %d does not match %d rows", dt.RowCount, len(dt.Rows))
+		}
+	})
+}
+
+func TestSyncDataTableRowCopy(t *testing.T) {
+	dt := NewDataTable("Lookup")
+	dt.AddColumn("Code", reflect.TypeOf(""), 0, "")
+	r := dt.NewRow()
+	r.Cells[0].Value = "A"
+	dt.AddRow(&r)
+	st := NewSyncDataTable(dt)
+
+	// Adding a column updates the column name index of the rows in the table but not of a kept copy
+	kept, _ := st.Row(0)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if kept.Value("Code") != "A" {
+				t.Error("expected A")
+			}
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		st.Write(func(dt *DataTable) {
+			dt.AddColumn("Extra"+strconv.Itoa(i), reflect.TypeOf(0), 0, "")
+		})
+	}
+	wg.Wait()
+
+	if kept.Value("Extra0") != nil {
+		t.Error("the copy sees a column added after it was made")
+	}
+}
+
+func TestRowValueConcurrentReads(t *testing.T) {
+	// A row built by hand has no column name index, reading it by name must not write to it
+	r := Row{Cells: []Cell{
+		{ColumnName: "ID", Value: 7},
+		{ColumnName: "Code", Value: "Seven"},
+	}, ColumnCount: 2}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if r.ValueString("code") != "Seven" {
+					t.Error("expected Seven")
+				}
+				if r.Value("ID") != 7 {
+					t.Error("expected 7")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSyncDataTableSwap(t *testing.T) {
+	first := NewDataTable("First")
+	st := NewSyncDataTable(first)
+
+	second := NewDataTable("Second")
+	second.AddColumn("ID", reflect.TypeOf(0), 0, "")
+	for i := 0; i < 5; i++ {
+		r := second.NewRow()
+		r.Cells[0].Value = i
+		second.AddRow(&r)
+	}
+
+	old := st.Swap(second)
+	if old != first {
+		t.Error("Swap should return the previous table")
+	}
+	if st.RowCount() != 5 {
+		t.Errorf("expected 5 rows, got %d", st.RowCount())
+	}
+	if _, ok := st.Row(5); ok {
+		t.Error("expected no row at index 5")
+	}
+}
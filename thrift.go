@@ -0,0 +1,309 @@
+package datatable
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// A minimal Thrift compact protocol encoder and decoder for the Parquet metadata
+
+// Thrift compact protocol types
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftWriter - writes a struct field by field. Nested structs are started with beginStruct or
+// listStruct and closed with endStruct
+type thriftWriter struct {
+	buf  []byte
+	last []int16
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	last := &t.last[len(t.last)-1]
+	if d := id - *last; d > 0 && d <= 15 {
+		t.buf = append(t.buf, byte(d)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.buf = binary.AppendVarint(t.buf, int64(id))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.buf = binary.AppendVarint(t.buf, v)
+}
+
+func (t *thriftWriter) bool(id int16, v bool) {
+	if v {
+		t.field(id, thriftTrue)
+	} else {
+		t.field(id, thriftFalse)
+	}
+}
+
+func (t *thriftWriter) binary(id int16, b []byte) {
+	t.field(id, thriftBinary)
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(b)))
+	t.buf = append(t.buf, b...)
+}
+
+func (t *thriftWriter) string(id int16, s string) {
+	t.binary(id, []byte(s))
+}
+
+// list - starts a list field of n elements
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elem)
+		return
+	}
+	t.buf = append(t.buf, 0xf0|elem)
+	t.buf = binary.AppendUvarint(t.buf, uint64(n))
+}
+
+// listI32 - writes an element of a list of i32
+func (t *thriftWriter) listI32(v int32) {
+	t.buf = binary.AppendVarint(t.buf, int64(v))
+}
+
+// listString - writes an element of a list of strings
+func (t *thriftWriter) listString(s string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+// beginStruct - starts a struct field
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.last = append(t.last, 0)
+}
+
+// listStruct - starts a struct element of a list
+func (t *thriftWriter) listStruct() {
+	t.last = append(t.last, 0)
+}
+
+func (t *thriftWriter) endStruct() {
+	t.buf = append(t.buf, 0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+// bytes - ends the top level struct and returns the encoded bytes
+func (t *thriftWriter) bytes() []byte {
+	return append(t.buf, 0)
+}
+
+// thriftFields - a decoded struct. Values are bool, int64, float64, []byte, []interface{} or thriftFields
+type thriftFields map[int16]interface{}
+
+func (f thriftFields) int(id int16, def int64) int64 {
+	if v, ok := f[id].(int64); ok {
+		return v
+	}
+	return def
+}
+
+func (f thriftFields) bool(id int16, def bool) bool {
+	if v, ok := f[id].(bool); ok {
+		return v
+	}
+	return def
+}
+
+func (f thriftFields) string(id int16) string {
+	b, _ := f[id].([]byte)
+	return string(b)
+}
+
+func (f thriftFields) has(id int16) bool {
+	_, ok := f[id]
+	return ok
+}
+
+func (f thriftFields) fields(id int16) thriftFields {
+	s, _ := f[id].(thriftFields)
+	return s
+}
+
+func (f thriftFields) list(id int16) []interface{} {
+	l, _ := f[id].([]interface{})
+	return l
+}
+
+// thriftReader - decodes compact protocol values
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+var errThriftData = errors.New("invalid thrift data")
+
+// readThriftStruct - decodes a struct and returns it with the number of bytes read
+func readThriftStruct(b []byte) (thriftFields, int, error) {
+	r := &thriftReader{buf: b}
+	v, err := r.value(thriftStruct, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v.(thriftFields), r.pos, nil
+}
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errThriftData
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errThriftData
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) varint() (int64, error) {
+	v, n := binary.Varint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errThriftData
+	}
+	r.pos += n
+	return v, nil
+}
+
+// value - decodes a value of a type. Depth limits nesting in malformed data
+func (r *thriftReader) value(typ byte, depth int) (interface{}, error) {
+	if depth > 64 {
+		return nil, errThriftData
+	}
+
+	switch typ {
+	case thriftTrue, thriftFalse:
+		// Only list elements get here, as one byte each
+		b, err := r.byte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := r.byte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return r.varint()
+	case thriftDouble:
+		if r.pos+8 > len(r.buf) {
+			return nil, errThriftData
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.buf[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case thriftBinary:
+		n, err := r.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(r.buf)-r.pos) {
+			return nil, errThriftData
+		}
+		b := r.buf[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return b, nil
+	case thriftList, thriftSet:
+		h, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(h >> 4)
+		if n == 15 {
+			if n, err = r.uvarint(); err != nil {
+				return nil, err
+			}
+		}
+		if n > uint64(len(r.buf)-r.pos) {
+			return nil, errThriftData
+		}
+		l := make([]interface{}, n)
+		for i := range l {
+			if l[i], err = r.value(h&0x0f, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case thriftMap:
+		n, err := r.uvarint()
+		if err != nil || n == 0 {
+			return nil, err
+		}
+		kv, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			if _, err := r.value(kv>>4, depth+1); err != nil {
+				return nil, err
+			}
+			if _, err := r.value(kv&0x0f, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStruct:
+		fields := make(thriftFields)
+		var last int16
+		for {
+			h, err := r.byte()
+			if err != nil {
+				return nil, err
+			}
+			if h == 0 {
+				return fields, nil
+			}
+
+			id := last + int16(h>>4)
+			if h>>4 == 0 {
+				v, err := r.varint()
+				if err != nil {
+					return nil, err
+				}
+				id = int16(v)
+			}
+			last = id
+
+			switch ft := h & 0x0f; ft {
+			case thriftTrue, thriftFalse:
+				fields[id] = ft == thriftTrue
+			default:
+				if fields[id], err = r.value(ft, depth+1); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown thrift type %d", typ)
+}
@@ -0,0 +1,132 @@
+package datatable
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// normalizeValue - converts a cell value to a common type so that values read from different
+// drivers compare equal. Integers become int64, floats with no fraction become int64,
+// []uint8 becomes string and times are set to UTC
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint:
+		return normalizeUint(uint64(v))
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return normalizeUint(v)
+	case float32:
+		return normalizeFloat(float64(v))
+	case float64:
+		return normalizeFloat(v)
+	case []uint8:
+		return string(v)
+	case time.Time:
+		return v.UTC()
+	}
+	return value
+}
+
+func normalizeUint(v uint64) interface{} {
+	if v <= math.MaxInt64 {
+		return int64(v)
+	}
+	return v
+}
+
+func normalizeFloat(v float64) interface{} {
+	if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+		return int64(v)
+	}
+	return v
+}
+
+// valuesEqual - compares two cell values after normalizing them
+func valuesEqual(a, b interface{}) bool {
+	na, nb := normalizeValue(a), normalizeValue(b)
+	if ta, ok := na.(time.Time); ok {
+		tb, ok := nb.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return reflect.DeepEqual(na, nb)
+}
+
+// keyString - builds a map key from the normalized values. Values of different normalized
+// types never produce the same key. Each value is prefixed with its length, so values holding
+// any bytes cannot run into the next one
+func keyString(values ...interface{}) string {
+	var b []byte
+	for _, v := range values {
+		b = appendKeyPart(b, keyPart(v))
+	}
+	return string(b)
+}
+
+// keyPart - returns the key of a single normalized value
+func keyPart(value interface{}) string {
+	switch nv := normalizeValue(value).(type) {
+	case nil:
+		return "n"
+	case time.Time:
+		return "t" + nv.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%T:%v", nv, nv)
+	}
+}
+
+// appendKeyPart - appends the length of a key part and the part to a key
+func appendKeyPart(b []byte, part string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(part)))
+	return append(b, part...)
+}
+
+// toFloat64 - converts a numeric value to float64. Returns false for other types
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
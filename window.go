@@ -0,0 +1,165 @@
+package datatable
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// WindowFunc - a function computed over a window of rows
+type WindowFunc int
+
+// Window functions
+const (
+	WindowRowNumber  WindowFunc = iota // sequential number of the row within its partition, starting at 1
+	WindowRank                         // rank with gaps after ties
+	WindowDenseRank                    // rank without gaps after ties
+	WindowLag                          // value of a row before the current row
+	WindowLead                         // value of a row after the current row
+	WindowRunningSum                   // sum from the first row of the partition up to the current row
+	WindowRunningAvg                   // average from the first row of the partition up to the current row
+	WindowMovingAvg                    // average of the current row and the Size-1 rows before it
+)
+
+// WindowSpec - describes a window column
+type WindowSpec struct {
+	PartitionBy []string    // columns that split the rows into independent partitions
+	OrderBy     []string    // columns that order rows within a partition. A leading "-" sorts descending
+	Func        WindowFunc  // the function to compute
+	Column      string      // the value column for lag, lead, running and moving functions
+	Offset      int         // number of rows back for lag or ahead for lead. Defaults to 1
+	Size        int         // number of rows in a moving average
+	Default     interface{} // value for lag and lead when the offset row is outside the partition
+}
+
+// AddWindowColumn - adds a column computed by a window function. The rows of the table keep their order.
+// Ranks and row numbers are int64, averages are float64, sums follow the value column and lag and lead
+// keep the type of the value column
+func (dt *DataTable) AddWindowColumn(name string, spec WindowSpec) error {
+	if dt.columnIndex(name) != -1 {
+		return fmt.Errorf("column %s already exists", name)
+	}
+
+	part := make([]int, len(spec.PartitionBy))
+	for i, p := range spec.PartitionBy {
+		if part[i] = dt.columnIndex(p); part[i] == -1 {
+			return fmt.Errorf("column %s does not exist", p)
+		}
+	}
+
+	order, err := dt.parseSortColumns(spec.OrderBy)
+	if err != nil {
+		return err
+	}
+
+	vidx := -1
+	var vtype reflect.Type
+	switch spec.Func {
+	case WindowRowNumber, WindowRank, WindowDenseRank:
+		vtype = reflect.TypeOf(int64(0))
+	case WindowLag, WindowLead, WindowRunningSum, WindowRunningAvg, WindowMovingAvg:
+		if vidx = dt.columnIndex(spec.Column); vidx == -1 {
+			return fmt.Errorf("column %s does not exist", spec.Column)
+		}
+		vtype = dt.Columns[vidx].Type
+		switch spec.Func {
+		case WindowRunningSum:
+			vtype = aggregateType(AggSum, vtype)
+		case WindowRunningAvg, WindowMovingAvg:
+			vtype = aggregateType(AggAvg, vtype)
+		}
+	default:
+		return errors.New("unknown window function")
+	}
+
+	if spec.Func == WindowMovingAvg && spec.Size <= 0 {
+		return errors.New("moving average requires a positive size")
+	}
+
+	offset := spec.Offset
+	if offset <= 0 {
+		offset = 1
+	}
+
+	// Group row indexes by partition keeping the table order, then sort each partition
+	groups := make(map[string]int)
+	var partitions [][]int
+	for i := range dt.Rows {
+		k := rowKeyString(&dt.Rows[i], part)
+		g, ok := groups[k]
+		if !ok {
+			g = len(partitions)
+			groups[k] = g
+			partitions = append(partitions, nil)
+		}
+		partitions[g] = append(partitions[g], i)
+	}
+
+	results := make([]interface{}, len(dt.Rows))
+	for _, rows := range partitions {
+		if len(order) > 0 {
+			slices.SortStableFunc(rows, func(a, b int) int {
+				return compareRows(&dt.Rows[a], &dt.Rows[b], order)
+			})
+		}
+
+		var agg *aggregator
+		rank, dense := int64(0), int64(0)
+		for n, ri := range rows {
+			switch spec.Func {
+			case WindowRowNumber:
+				results[ri] = int64(n + 1)
+			case WindowRank, WindowDenseRank:
+				if n == 0 || compareRows(&dt.Rows[rows[n-1]], &dt.Rows[ri], order) != 0 {
+					rank = int64(n + 1)
+					dense++
+				}
+				if spec.Func == WindowRank {
+					results[ri] = rank
+				} else {
+					results[ri] = dense
+				}
+			case WindowLag, WindowLead:
+				o := n - offset
+				if spec.Func == WindowLead {
+					o = n + offset
+				}
+				if o >= 0 && o < len(rows) {
+					results[ri] = copyValue(dt.Rows[rows[o]].Cells[vidx].Value)
+				} else {
+					results[ri] = spec.Default
+				}
+			case WindowRunningSum, WindowRunningAvg:
+				if agg == nil {
+					agg = newAggregator(AggSum)
+					if spec.Func == WindowRunningAvg {
+						agg.agg = AggAvg
+					}
+				}
+				agg.add(cellValue(&dt.Rows[ri], vidx))
+				results[ri] = agg.resultAs(vtype)
+			case WindowMovingAvg:
+				ma := newAggregator(AggAvg)
+				for m := max(0, n-spec.Size+1); m <= n; m++ {
+					ma.add(cellValue(&dt.Rows[rows[m]], vidx))
+				}
+				results[ri] = ma.result()
+			}
+		}
+	}
+
+	dbtype := ""
+	var length int64
+	if vidx != -1 && (spec.Func == WindowLag || spec.Func == WindowLead) {
+		dbtype = dt.Columns[vidx].DBType
+		length = dt.Columns[vidx].Length
+	}
+	dt.AddColumns([]Column{{Name: name, Type: vtype, DBType: dbtype, Length: length}})
+
+	last := len(dt.Columns) - 1
+	for i := range dt.Rows {
+		dt.Rows[i].Cells[last].Value = results[i]
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package datatable
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func windowValues(dt *DataTable, col string) string {
+	var vals []interface{}
+	for _, r := range dt.Rows {
+		vals = append(vals, r.Value(col))
+	}
+	return fmt.Sprint(vals)
+}
+
+func TestAddWindowColumn(t *testing.T) {
+	dt := NewDataTable("Sales")
+	dt.AddColumns([]Column{
+		{Name: "Region", Type: reflect.TypeOf("")},
+		{Name: "Month", Type: reflect.TypeOf("")},
+		{Name: "Amount", Type: reflect.TypeOf(0)},
+	})
+	for _, v := range []struct {
+		region, month string
+		amount        int
+	}{
+		{"North", "Jan", 10}, {"North", "Feb", 20}, {"South", "Jan", 5},
+		{"North", "Jan", 7}, {"South", "Mar", 1}, {"East", "Feb", 3},
+	} {
+		r := dt.NewRow()
+		r.Cells[0].Value = v.region
+		r.Cells[1].Value = v.month
+		r.Cells[2].Value = v.amount
+		dt.AddRow(&r)
+	}
+
+	tests := []struct {
+		name string
+		spec WindowSpec
+		want string
+	}{
+		{"RowNo", WindowSpec{PartitionBy: []string{"Region"}, OrderBy: []string{"-Amount"}, Func: WindowRowNumber}, "[2 1 1 3 2 1]"},
+		{"Rank", WindowSpec{OrderBy: []string{"Month"}, Func: WindowRank}, "[3 1 3 3 6 1]"},
+		{"Dense", WindowSpec{OrderBy: []string{"Month"}, Func: WindowDenseRank}, "[2 1 2 2 3 1]"},
+		{"Prev", WindowSpec{PartitionBy: []string{"Region"}, Func: WindowLag, Column: "Amount", Default: 0}, "[0 10 0 20 5 0]"},
+		{"Next", WindowSpec{PartitionBy: []string{"Region"}, Func: WindowLead, Column: "Amount", Offset: 2}, "[7 <nil> <nil> <nil> <nil> <nil>]"},
+		{"Total", WindowSpec{PartitionBy: []string{"Region"}, Func: WindowRunningSum, Column: "Amount"}, "[10 30 5 37 6 3]"},
+		{"Avg", WindowSpec{Func: WindowRunningAvg, Column: "Amount"}, "[10 15 11.666666666666666 10.5 8.6 7.666666666666667]"},
+		{"Moving", WindowSpec{Func: WindowMovingAvg, Column: "Amount", Size: 2}, "[10 15 12.5 6 4 2]"},
+	}
+
+	for _, tt := range tests {
+		if err := dt.AddWindowColumn(tt.name, tt.spec); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := windowValues(dt, tt.name); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+
+	if dt.Columns[dt.columnIndex("Total")].Type != reflect.TypeOf(int64(0)) {
+		t.Error("expected an int64 running sum")
+	}
+	if dt.Columns[dt.columnIndex("Moving")].Type != reflect.TypeOf(float64(0)) {
+		t.Error("expected a float64 moving average")
+	}
+
+	if err := dt.AddWindowColumn("Total", WindowSpec{Func: WindowRowNumber}); err == nil {
+		t.Error("expected an error for an existing column")
+	}
+	if err := dt.AddWindowColumn("X", WindowSpec{Func: WindowMovingAvg, Column: "Amount"}); err == nil {
+		t.Error("expected an error for a moving average without a size")
+	}
+	if err := dt.AddWindowColumn("X", WindowSpec{Func: WindowLag, Column: "Nope"}); err == nil {
+		t.Error("expected an error for a missing column")
+	}
+}
+
+func TestAddWindowColumnDecimalBytes(t *testing.T) {
+	dt := NewDataTable("Prices")
+	dt.AddColumns([]Column{{Name: "Amount", Type: reflect.TypeOf([]byte{}), DBType: "NUMERIC(10,2)"}})
+	for _, v := range []string{"9.5", "10", "100", "-2"} {
+		r := dt.NewRow()
+		r.Cells[0].Value = []byte(v)
+		dt.AddRow(&r)
+	}
+
+	// As text, 10 and 100 would sort before 9.5
+	if err := dt.AddWindowColumn("Rank", WindowSpec{OrderBy: []string{"Amount"}, Func: WindowRank}); err != nil {
+		t.Fatal(err)
+	}
+	if got := windowValues(dt, "Rank"); got != "[2 3 4 1]" {
+		t.Errorf("unexpected ranks %s", got)
+	}
+}
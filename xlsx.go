@@ -0,0 +1,641 @@
+package datatable
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	xlsxMain = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRels = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// Cell styles in styles.xml as written by WriteXLSX
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleDateTime
+	xlsxStyleDate
+	xlsxStyleHeader
+)
+
+// xlsxEpoch - day zero of the Excel 1900 date system
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// WriteXLSX - writes the tables as the sheets of an Excel workbook. Each sheet has a header row with the
+// column names. Numbers, booleans and dates are written as typed cells and everything else as text.
+// Column widths follow Column.Length, or the width of the column name if there is no length
+func WriteXLSX(w io.Writer, sheets ...*DataTable) error {
+	if len(sheets) == 0 {
+		return errors.New("no sheets to write")
+	}
+
+	names := xlsxSheetNames(sheets)
+	zw := zip.NewWriter(w)
+
+	write := func(name, content string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+content)
+		return err
+	}
+
+	var ct, wb, rels strings.Builder
+	ct.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	wb.WriteString(`<workbook xmlns="` + xlsxMain + `" xmlns:r="` + xlsxRels + `"><sheets>`)
+	rels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="` + xlsxRels + `/styles" Target="styles.xml"/>`)
+	for i, name := range names {
+		n := strconv.Itoa(i + 1)
+		ct.WriteString(`<Override PartName="/xl/worksheets/sheet` + n + `.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`)
+		wb.WriteString(`<sheet name="` + xmlAttr(name) + `" sheetId="` + n + `" r:id="rId` + n + `"/>`)
+		rels.WriteString(`<Relationship Id="rId` + n + `" Type="` + xlsxRels + `/worksheet" Target="worksheets/sheet` + n + `.xml"/>`)
+	}
+	ct.WriteString(`</Types>`)
+	wb.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", ct.String()},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + xlsxRels + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", wb.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		if err := write(p.name, p.content); err != nil {
+			return err
+		}
+	}
+
+	for i, dt := range sheets {
+		if err := write("xl/worksheets/sheet"+strconv.Itoa(i+1)+".xml", xlsxSheet(dt)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xlsxStyles - cell formats for plain cells, date and time cells, date cells and the bold header row
+const xlsxStyles = `<styleSheet xmlns="` + xlsxMain + `">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm:ss"/><numFmt numFmtId="165" formatCode="yyyy\-mm\-dd"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// xlsxSheetNames - returns valid and unique sheet names for the tables
+func xlsxSheetNames(sheets []*DataTable) []string {
+	names := make([]string, len(sheets))
+	used := make(map[string]bool)
+	for i, dt := range sheets {
+		name := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`[]:*?/\`, r) {
+				return '_'
+			}
+			return r
+		}, dt.Name)
+		name = strings.Trim(name, "'")
+		if name == "" {
+			name = "Sheet" + strconv.Itoa(i+1)
+		}
+		if r := []rune(name); len(r) > 31 {
+			name = string(r[:31])
+		}
+
+		base := name
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			suffix := " (" + strconv.Itoa(n) + ")"
+			r := []rune(base)
+			if len(r)+len(suffix) > 31 {
+				r = r[:31-len(suffix)]
+			}
+			name = string(r) + suffix
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+// xlsxSheet - returns the worksheet part of a table
+func xlsxSheet(dt *DataTable) string {
+	var sb strings.Builder
+	sb.WriteString(`<worksheet xmlns="` + xlsxMain + `">`)
+
+	if len(dt.Columns) > 0 {
+		sb.WriteString(`<cols>`)
+		for i, col := range dt.Columns {
+			n := strconv.Itoa(i + 1)
+			sb.WriteString(`<col min="` + n + `" max="` + n + `" width="` + strconv.Itoa(xlsxColumnWidth(col)) + `" customWidth="1"/>`)
+		}
+		sb.WriteString(`</cols>`)
+	}
+
+	sb.WriteString(`<sheetData>`)
+	sb.WriteString(`<row r="1">`)
+	for i, col := range dt.Columns {
+		xlsxInlineString(&sb, xlsxCellRef(i, 1), col.Name, xlsxStyleHeader)
+	}
+	sb.WriteString(`</row>`)
+
+	for r := range dt.Rows {
+		row := &dt.Rows[r]
+		rn := r + 2
+		sb.WriteString(`<row r="` + strconv.Itoa(rn) + `">`)
+		for i, col := range dt.Columns {
+			if i >= len(row.Cells) || row.Cells[i].Value == nil {
+				continue
+			}
+			xlsxCell(&sb, xlsxCellRef(i, rn), row.Cells[i].Value, col)
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+// xlsxColumnWidth - returns the width of a column in characters
+func xlsxColumnWidth(col Column) int {
+	w := len(col.Name) + 2
+	switch {
+	case col.Type == reflect.TypeOf(time.Time{}):
+		w = max(w, 20)
+	case col.Length > 0:
+		w = max(w, int(min(col.Length, 100))+2)
+	}
+	return max(w, 10)
+}
+
+// xlsxCell - writes a typed cell
+func xlsxCell(sb *strings.Builder, ref string, value interface{}, col Column) {
+	switch v := value.(type) {
+	case string:
+		xlsxInlineString(sb, ref, v, xlsxStyleDefault)
+	case []byte:
+		// Drivers return DECIMAL and other numeric columns as text, which is written as a number when it is one
+		if isNumericDBType(col.DBType) {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(string(v)), 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				sb.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatFloat(f, 'g', -1, 64) + `</v></c>`)
+				return
+			}
+		}
+		if isBinaryColumn(col) {
+			xlsxInlineString(sb, ref, base64.StdEncoding.EncodeToString(v), xlsxStyleDefault)
+			return
+		}
+		xlsxInlineString(sb, ref, string(v), xlsxStyleDefault)
+	case bool:
+		b := "0"
+		if v {
+			b = "1"
+		}
+		sb.WriteString(`<c r="` + ref + `" t="b"><v>` + b + `</v></c>`)
+	case time.Time:
+		style := xlsxStyleDateTime
+		if strings.EqualFold(col.DBType, "DATE") {
+			style = xlsxStyleDate
+		}
+		sb.WriteString(`<c r="` + ref + `" s="` + strconv.Itoa(style) + `"><v>` + strconv.FormatFloat(xlsxSerial(v), 'f', -1, 64) + `</v></c>`)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		sb.WriteString(`<c r="` + ref + `"><v>` + fmt.Sprint(v) + `</v></c>`)
+	case float32, float64:
+		f, _ := toFloat64(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			xlsxInlineString(sb, ref, fmt.Sprint(v), xlsxStyleDefault)
+			return
+		}
+		sb.WriteString(`<c r="` + ref + `"><v>` + strconv.FormatFloat(f, 'g', -1, 64) + `</v></c>`)
+	default:
+		xlsxInlineString(sb, ref, fmt.Sprint(v), xlsxStyleDefault)
+	}
+}
+
+func xlsxInlineString(sb *strings.Builder, ref, s string, style int) {
+	sb.WriteString(`<c r="` + ref + `"`)
+	if style != xlsxStyleDefault {
+		sb.WriteString(` s="` + strconv.Itoa(style) + `"`)
+	}
+	sb.WriteString(` t="inlineStr"><is><t xml:space="preserve">`)
+	xml.EscapeText(sb, []byte(s))
+	sb.WriteString(`</t></is></c>`)
+}
+
+// xlsxSerial - returns the Excel serial date of the wall clock time
+func xlsxSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	d := wall.Sub(xlsxEpoch)
+	days := math.Floor(d.Hours() / 24)
+	secs := (d - time.Duration(days)*24*time.Hour).Seconds()
+	return days + math.Round(secs*1000)/(86400*1000)
+}
+
+// xlsxTime - returns the time of an Excel serial date, rounded to the millisecond
+func xlsxTime(serial float64) time.Time {
+	days := math.Floor(serial)
+	ms := math.Round((serial - days) * 86400 * 1000)
+	return xlsxEpoch.AddDate(0, 0, int(days)).Add(time.Duration(ms) * time.Millisecond)
+}
+
+// xlsxCellRef - returns the A1 reference of a zero based column and a row number
+func xlsxCellRef(col, row int) string {
+	var b []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		b = append([]byte{byte('A' + (col-1)%26)}, b...)
+	}
+	return string(b) + strconv.Itoa(row)
+}
+
+// Sheet limits of the XLSX format
+const (
+	xlsxMaxColumns = 16384
+	xlsxMaxRows    = 1048576
+)
+
+// xlsxParseRef - returns the zero based column and the row number of an A1 reference, and false if it is
+// not a valid reference within the sheet limits
+func xlsxParseRef(ref string) (int, int, bool) {
+	col, i := 0, 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		if col = col*26 + int(ref[i]-'A'+1); col > xlsxMaxColumns {
+			return 0, 0, false
+		}
+	}
+	row, err := strconv.Atoi(ref[i:])
+	if col == 0 || err != nil || row < 1 || row > xlsxMaxRows {
+		return 0, 0, false
+	}
+	return col - 1, row, true
+}
+
+type xlsxRelationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	s := t.T
+	for _, r := range t.Runs {
+		s += r.T
+	}
+	return s
+}
+
+type xlsxStyleSheet struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R  string    `xml:"r,attr"`
+			T  string    `xml:"t,attr"`
+			S  int       `xml:"s,attr"`
+			V  string    `xml:"v"`
+			Is *xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX - reads a sheet of an Excel workbook, or the first sheet if no name is given. Row 1 holds the
+// column names, which default to Column1, Column2 and so on if it is empty or missing. Column types are inferred from the cells below it: bool, int64, float64 and
+// time.Time if all the cells of a column agree, and string otherwise. Empty cells are read as NULL
+func ReadXLSX(r io.Reader, sheet string) (*DataTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	decode := func(name string, v interface{}) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("the workbook has no part %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return xml.NewDecoder(rc).Decode(v)
+	}
+
+	var wb xlsxWorkbook
+	if err := decode("xl/workbook.xml", &wb); err != nil {
+		return nil, err
+	}
+	var rels xlsxRelationships
+	if err := decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	if len(wb.Sheets) == 0 {
+		return nil, errors.New("the workbook has no sheets")
+	}
+
+	idx := 0
+	if sheet != "" {
+		idx = -1
+		for i, s := range wb.Sheets {
+			if strings.EqualFold(s.Name, sheet) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return nil, fmt.Errorf("sheet %s does not exist", sheet)
+		}
+	}
+
+	var target string
+	for _, rel := range rels.Items {
+		if rel.ID == wb.Sheets[idx].RID {
+			target = rel.Target
+			break
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("sheet %s has no worksheet part", wb.Sheets[idx].Name)
+	}
+	if strings.HasPrefix(target, "/") {
+		target = target[1:]
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	var shared []string
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decode("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		shared = make([]string, len(sst.Items))
+		for i, si := range sst.Items {
+			shared[i] = si.String()
+		}
+	}
+
+	var dateStyles []bool
+	if _, ok := files["xl/styles.xml"]; ok {
+		var ss xlsxStyleSheet
+		if err := decode("xl/styles.xml", &ss); err != nil {
+			return nil, err
+		}
+		codes := make(map[int]string)
+		for _, nf := range ss.NumFmts {
+			codes[nf.ID] = nf.Code
+		}
+		dateStyles = make([]bool, len(ss.CellXfs))
+		for i, xf := range ss.CellXfs {
+			dateStyles[i] = xlsxIsDateFormat(xf.NumFmtID, codes[xf.NumFmtID])
+		}
+	}
+
+	var ws xlsxWorksheet
+	if err := decode(target, &ws); err != nil {
+		return nil, err
+	}
+
+	// Cell values by row and column. Values are string, bool, float64, int64 or time.Time
+	var header []string
+	var grid [][]interface{}
+	rowNum := 0
+	for _, row := range ws.Rows {
+		// Rows without a number follow the previous row
+		rowNum++
+		if row.R != 0 {
+			rowNum = row.R
+		}
+		if rowNum < 1 || rowNum > xlsxMaxRows {
+			return nil, fmt.Errorf("invalid row number %d", rowNum)
+		}
+
+		var vals []interface{}
+		for ci, c := range row.Cells {
+			col := ci
+			if c.R != "" {
+				var ok bool
+				if col, _, ok = xlsxParseRef(c.R); !ok {
+					return nil, fmt.Errorf("invalid cell reference %q", c.R)
+				}
+			}
+			if col >= xlsxMaxColumns {
+				return nil, fmt.Errorf("row %d has more than %d cells", rowNum, xlsxMaxColumns)
+			}
+
+			var v interface{}
+			switch c.T {
+			case "s":
+				if i, err := strconv.Atoi(c.V); err == nil && i >= 0 && i < len(shared) {
+					v = shared[i]
+				}
+			case "inlineStr":
+				if c.Is != nil {
+					v = c.Is.String()
+				}
+			case "str", "e":
+				v = c.V
+			case "b":
+				v = c.V == "1"
+			case "d":
+				if t, err := time.Parse(time.RFC3339Nano, c.V); err == nil {
+					v = t
+				} else {
+					v = c.V
+				}
+			default:
+				if c.V == "" {
+					break
+				}
+				f, err := strconv.ParseFloat(c.V, 64)
+				switch {
+				case err != nil:
+					v = c.V
+				case c.S >= 0 && c.S < len(dateStyles) && dateStyles[c.S]:
+					v = xlsxTime(f)
+				case f == math.Trunc(f) && math.Abs(f) < 1<<53:
+					v = int64(f)
+				default:
+					v = f
+				}
+			}
+
+			for len(vals) <= col {
+				vals = append(vals, nil)
+			}
+			vals[col] = v
+		}
+
+		if rowNum == 1 {
+			header = make([]string, len(vals))
+			for i, v := range vals {
+				if v != nil {
+					header[i] = fmt.Sprint(v)
+				}
+			}
+			continue
+		}
+		grid = append(grid, vals)
+	}
+
+	width := len(header)
+	for _, vals := range grid {
+		width = max(width, len(vals))
+	}
+
+	cols := make([]Column, width)
+	for i := range cols {
+		name := ""
+		if i < len(header) {
+			name = strings.TrimSpace(header[i])
+		}
+		if name == "" {
+			name = "Column" + strconv.Itoa(i+1)
+		}
+		cols[i] = Column{Name: name, Type: xlsxInferType(grid, i)}
+	}
+
+	dt := NewDataTable(wb.Sheets[idx].Name)
+	dt.AddColumns(cols)
+	for _, vals := range grid {
+		row := dt.NewRow()
+		for i := range row.Cells {
+			if i < len(vals) && vals[i] != nil {
+				row.Cells[i].Value = xlsxConvert(vals[i], dt.Columns[i].Type)
+			}
+		}
+		dt.AddRow(&row)
+	}
+	return dt, nil
+}
+
+// xlsxInferType - returns the type shared by all non-empty cells of a column
+func xlsxInferType(grid [][]interface{}, col int) reflect.Type {
+	var t reflect.Type
+	for _, vals := range grid {
+		if col >= len(vals) || vals[col] == nil {
+			continue
+		}
+		vt := reflect.TypeOf(vals[col])
+		switch {
+		case t == nil || t == vt:
+			t = vt
+		case isNumberType(t) && isNumberType(vt):
+			t = reflect.TypeOf(0.0)
+		default:
+			return reflect.TypeOf("")
+		}
+	}
+	if t == nil {
+		return reflect.TypeOf("")
+	}
+	return t
+}
+
+func isNumberType(t reflect.Type) bool {
+	return t.Kind() == reflect.Int64 || t.Kind() == reflect.Float64
+}
+
+// xlsxConvert - converts a cell value to the inferred column type
+func xlsxConvert(v interface{}, t reflect.Type) interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		switch x := v.(type) {
+		case string:
+			return x
+		case time.Time:
+			return x.Format("2006-01-02 15:04:05")
+		case float64:
+			return strconv.FormatFloat(x, 'f', -1, 64)
+		}
+		return fmt.Sprint(v)
+	case reflect.Float64:
+		f, _ := toFloat64(v)
+		return f
+	}
+	return v
+}
+
+// xlsxIsDateFormat - returns true if a number format displays dates or times
+func xlsxIsDateFormat(id int, code string) bool {
+	switch {
+	case id >= 14 && id <= 22, id >= 45 && id <= 47:
+		return true
+	case code == "":
+		return false
+	}
+
+	// Ignore quoted text, escaped characters and bracketed sections such as colors
+	var sb strings.Builder
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case '"':
+			for i++; i < len(code) && code[i] != '"'; i++ {
+			}
+		case '\\':
+			i++
+		case '[':
+			for i++; i < len(code) && code[i] != ']'; i++ {
+			}
+		default:
+			sb.WriteByte(code[i])
+		}
+	}
+	return strings.ContainsAny(strings.ToLower(sb.String()), "dmyhs")
+}
@@ -0,0 +1,285 @@
+package datatable
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXLSXRoundTrip(t *testing.T) {
+	dt := NewDataTable("Sales/2024")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Customer", Type: reflect.TypeOf(""), Length: 30},
+		{Name: "Amount", Type: reflect.TypeOf(0.0)},
+		{Name: "Sold", Type: reflect.TypeOf(time.Time{})},
+		{Name: "Paid", Type: reflect.TypeOf(false)},
+	})
+	for _, vals := range [][]interface{}{
+		{1, "Acme <Ltd>", 10.25, time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC), true},
+		{2, "  Beta  ", 3.0, time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC), false},
+		{3, nil, nil, nil, nil},
+	} {
+		r := dt.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		dt.AddRow(&r)
+	}
+	other := NewDataTable("Sales/2024")
+	other.AddColumn("Note", reflect.TypeOf(""), 0, "")
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, dt, other); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	got, err := ReadXLSX(bytes.NewReader(data), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Sales_2024" {
+		t.Fatalf("unexpected sheet name %s", got.Name)
+	}
+
+	wantTypes := []reflect.Type{reflect.TypeOf(int64(0)), reflect.TypeOf(""), reflect.TypeOf(0.0), reflect.TypeOf(time.Time{}), reflect.TypeOf(false)}
+	for i, col := range got.Columns {
+		if col.Name != dt.Columns[i].Name || col.Type != wantTypes[i] {
+			t.Errorf("column %d: got %s %v, expected %s %v", i, col.Name, col.Type, dt.Columns[i].Name, wantTypes[i])
+		}
+	}
+
+	want := [][]interface{}{
+		{int64(1), "Acme <Ltd>", 10.25, time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC), true},
+		{int64(2), "  Beta  ", 3.0, time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC), false},
+		{int64(3), nil, nil, nil, nil},
+	}
+	if len(got.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(got.Rows))
+	}
+	for i, vals := range want {
+		for j, v := range vals {
+			if g := got.Rows[i].Cells[j].Value; !reflect.DeepEqual(g, v) {
+				t.Errorf("row %d, column %d: got %#v, expected %#v", i, j, g, v)
+			}
+		}
+	}
+
+	second, err := ReadXLSX(bytes.NewReader(data), "sales_2024 (2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Columns) != 1 || second.Columns[0].Name != "note" || len(second.Rows) != 0 {
+		t.Fatalf("unexpected second sheet %+v", second.Columns)
+	}
+
+	if _, err := ReadXLSX(bytes.NewReader(data), "Missing"); err == nil {
+		t.Fatal("expected an error for a missing sheet")
+	}
+}
+
+func TestWriteXLSXColumnWidths(t *testing.T) {
+	dt := NewDataTable("Sales/2024")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(0)},
+		{Name: "Customer", Type: reflect.TypeOf(""), Length: 30},
+		{Name: "Amount", Type: reflect.TypeOf(0.0)},
+		{Name: "Sold", Type: reflect.TypeOf(time.Time{})},
+		{Name: "Paid", Type: reflect.TypeOf(false)},
+	})
+	r := dt.NewRow()
+	r.Cells[3].Value = time.Date(2024, 2, 29, 13, 45, 30, 0, time.UTC)
+	r.Cells[4].Value = true
+	dt.AddRow(&r)
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, dt); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			b, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(b)
+		}
+	}
+
+	for _, want := range []string{
+		`<col min="1" max="1" width="10" customWidth="1"/>`,
+		`<col min="2" max="2" width="32" customWidth="1"/>`,
+		`<col min="4" max="4" width="20" customWidth="1"/>`,
+		`<c r="A1" s="3" t="inlineStr"><is><t xml:space="preserve">ID</t></is></c>`,
+		`<c r="D2" s="1"><v>45351.57326388889</v></c>`,
+		`<c r="E2" t="b"><v>1</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("missing %s in\n%s", want, sheet)
+		}
+	}
+}
+
+func TestReadXLSXSharedStrings(t *testing.T) {
+	// A workbook as saved by Excel, with shared strings, a custom date format and a gap in the cells
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Data" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="worksheet" Target="/xl/worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>Code</t></si><si><t>Due</t></si><si><r><t>Mi</t></r><r><t>xed</t></r></si><si><t>A1</t></si></sst>`,
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<numFmts count="1"><numFmt numFmtId="170" formatCode="[$-409]d\-mmm\-yy;@"/></numFmts>` +
+			`<cellXfs count="3"><xf numFmtId="0"/><xf numFmtId="170"/><xf numFmtId="4"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>2</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2" s="1"><v>45292</v></c><c r="C2" s="2"><v>1.5</v></c><c r="D2"><v>7</v></c></row>` +
+			`<row r="3"><c r="A3" t="str"><v>B2</v></c><c r="C3" s="2"><v>2</v></c><c r="D3" t="s"><v>3</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, _ := zw.Create(name)
+		io.WriteString(f, content)
+	}
+	zw.Close()
+
+	dt, err := ReadXLSX(&buf, "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"Code", "Due", "Column3", "Mixed"}
+	types := []reflect.Type{reflect.TypeOf(""), reflect.TypeOf(time.Time{}), reflect.TypeOf(0.0), reflect.TypeOf("")}
+	for i, col := range dt.Columns {
+		if col.Name != names[i] || col.Type != types[i] {
+			t.Errorf("column %d: got %s %v, expected %s %v", i, col.Name, col.Type, names[i], types[i])
+		}
+	}
+	if len(dt.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(dt.Rows))
+	}
+	if v := dt.Rows[0].Value("Due"); v != time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("unexpected date %v", v)
+	}
+	if v := dt.Rows[1].Value("Column3"); v != 2.0 {
+		t.Errorf("expected 2.0, got %#v", v)
+	}
+	if v := dt.Rows[0].Value("Mixed"); v != "7" {
+		t.Errorf("expected the number as text, got %#v", v)
+	}
+	if v := dt.Rows[1].Value("Due"); v != nil {
+		t.Errorf("expected NULL, got %#v", v)
+	}
+}
+
+func TestXLSXCellRef(t *testing.T) {
+	for _, tc := range []struct {
+		col, row int
+		ref      string
+	}{{0, 1, "A1"}, {25, 2, "Z2"}, {26, 3, "AA3"}, {701, 4, "ZZ4"}, {702, 5, "AAA5"}} {
+		if ref := xlsxCellRef(tc.col, tc.row); ref != tc.ref {
+			t.Errorf("got %s, expected %s", ref, tc.ref)
+		}
+		if col, row, ok := xlsxParseRef(tc.ref); !ok || col != tc.col || row != tc.row {
+			t.Errorf("%s: got %d %d %v", tc.ref, col, row, ok)
+		}
+	}
+
+	if col, row, ok := xlsxParseRef("XFD1048576"); !ok || col != xlsxMaxColumns-1 || row != xlsxMaxRows {
+		t.Errorf("XFD1048576: got %d %d %v", col, row, ok)
+	}
+	for _, ref := range []string{"XFE1", "A1048577", "A0", "1", "A", "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAA1"} {
+		if _, _, ok := xlsxParseRef(ref); ok {
+			t.Errorf("%s: expected an invalid reference", ref)
+		}
+	}
+}
+
+func TestReadXLSXRows(t *testing.T) {
+	read := func(sheetData string) (*DataTable, error) {
+		parts := map[string]string{
+			"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+				`<sheets><sheet name="Data" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+				`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+				sheetData + `</sheetData></worksheet>`,
+		}
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range parts {
+			f, _ := zw.Create(name)
+			io.WriteString(f, content)
+		}
+		zw.Close()
+		return ReadXLSX(&buf, "")
+	}
+
+	// Without row 1 the columns get default names and every row is data
+	dt, err := read(`<row r="2"><c r="A2" t="str"><v>x</v></c><c r="B2"><v>1</v></c></row><row><c t="str"><v>y</v></c></row>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dt.Columns) != 2 || dt.Columns[0].Name != "Column1" || len(dt.Rows) != 2 || dt.Rows[1].Value("Column1") != "y" {
+		t.Fatalf("unexpected table %v %v", dt.Columns, dt.Rows)
+	}
+
+	for _, sheetData := range []string{
+		`<row r="1"><c r="XFE1"><v>1</v></c></row>`,
+		`<row r="1"><c r="AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA1"><v>1</v></c></row>`,
+		`<row r="1048577"><c><v>1</v></c></row>`,
+	} {
+		if _, err := read(sheetData); err == nil {
+			t.Errorf("%s: expected an error", sheetData)
+		}
+	}
+}
+
+func TestWriteXLSXDecimalBytes(t *testing.T) {
+	dt := NewDataTable("Prices")
+	dt.AddColumns([]Column{
+		{Name: "Price", Type: reflect.TypeOf([]byte{}), DBType: "DECIMAL(10,2)"},
+		{Name: "Code", Type: reflect.TypeOf([]byte{}), DBType: "VARCHAR"},
+	})
+	for _, v := range [][2]string{{"12.50", "007"}, {" 1000 ", "x"}} {
+		r := dt.NewRow()
+		r.Cells[0].Value, r.Cells[1].Value = []byte(v[0]), []byte(v[1])
+		dt.AddRow(&r)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, dt); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadXLSX(bytes.NewReader(buf.Bytes()), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Columns[0].Type != reflect.TypeOf(0.0) || got.Columns[1].Type != reflect.TypeOf("") {
+		t.Fatalf("unexpected columns %+v", got.Columns)
+	}
+	want := [][]interface{}{{12.5, "007"}, {1000.0, "x"}}
+	for i, vals := range want {
+		for j, v := range vals {
+			if g := got.Rows[i].Cells[j].Value; !reflect.DeepEqual(g, v) {
+				t.Errorf("row %d, column %d: got %#v, expected %#v", i, j, g, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,691 @@
+package datatable
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// XML namespaces of the ADO.NET DataSet format
+const (
+	xmlnsSchema   = "http://www.w3.org/2001/XMLSchema"
+	xmlnsMSData   = "urn:schemas-microsoft-com:xml-msdata"
+	xmlnsDiffGram = "urn:schemas-microsoft-com:xml-diffgram-v1"
+)
+
+// XMLWriteMode - the form of the XML written by WriteXML
+type XMLWriteMode int
+
+// XML write modes, named after the ADO.NET XmlWriteMode values
+const (
+	XMLWriteSchema  XMLWriteMode = iota // rows with an inline XSD schema
+	XMLIgnoreSchema                     // rows only
+	XMLDiffGram                         // current rows marked as inserted or modified, with original and deleted rows in a before block
+)
+
+// XMLOptions - options for WriteXML
+type XMLOptions struct {
+	Mode        XMLWriteMode
+	DataSetName string     // name of the root element. Defaults to NewDataSet
+	Original    *DataTable // the table before changes for DiffGram mode. Rows are matched by primary key, or by position
+	// if there is none. Without an original table every row is written as inserted
+}
+
+// WriteXML - writes the table in the XML format of ADO.NET DataTable.WriteXml so that .NET services can read
+// it with ReadXml. NULL values are written as missing elements
+func (dt *DataTable) WriteXML(w io.Writer, opts XMLOptions) error {
+	ds := opts.DataSetName
+	if ds == "" {
+		ds = "NewDataSet"
+	}
+	ds = xmlEncodeName(ds)
+	tn := xmlEncodeName(dt.xmlTableName())
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" standalone=\"yes\"?>\n")
+
+	if opts.Mode == XMLDiffGram {
+		dt.writeDiffGram(bw, ds, tn, opts.Original)
+		return bw.Flush()
+	}
+
+	bw.WriteString("<" + ds + ">\n")
+	if opts.Mode == XMLWriteSchema {
+		dt.writeXMLSchema(bw, ds, tn)
+	}
+	for i := range dt.Rows {
+		dt.writeXMLRow(bw, "  ", tn, "", &dt.Rows[i])
+	}
+	bw.WriteString("</" + ds + ">\n")
+	return bw.Flush()
+}
+
+func (dt *DataTable) xmlTableName() string {
+	if dt.Name == "" {
+		return "Table1"
+	}
+	return dt.Name
+}
+
+// writeXMLSchema - writes the inline XSD schema of the table
+func (dt *DataTable) writeXMLSchema(bw *bufio.Writer, ds, tn string) {
+	bw.WriteString(`  <xs:schema id="` + ds + `" xmlns="" xmlns:xs="` + xmlnsSchema + `" xmlns:msdata="` + xmlnsMSData + "\">\n")
+	bw.WriteString(`    <xs:element name="` + ds + `" msdata:IsDataSet="true" msdata:MainDataTable="` + xmlAttr(tn) + "\" msdata:UseCurrentLocale=\"true\">\n")
+	bw.WriteString("      <xs:complexType>\n")
+	bw.WriteString("        <xs:choice minOccurs=\"0\" maxOccurs=\"unbounded\">\n")
+	bw.WriteString(`          <xs:element name="` + tn + "\">\n")
+	bw.WriteString("            <xs:complexType>\n")
+	bw.WriteString("              <xs:sequence>\n")
+
+	var keys []string
+	for _, col := range dt.Columns {
+		name := xmlEncodeName(col.Name)
+		occurs := ` minOccurs="0"`
+		if col.NotNull || col.PrimaryKey {
+			occurs = ""
+		}
+		if col.PrimaryKey {
+			keys = append(keys, name)
+		}
+
+		xsType := xmlSchemaType(col)
+		if xsType == "xs:string" && col.Length > 0 {
+			bw.WriteString(`                <xs:element name="` + name + `"` + occurs + ">\n")
+			bw.WriteString("                  <xs:simpleType>\n")
+			bw.WriteString("                    <xs:restriction base=\"xs:string\">\n")
+			bw.WriteString(`                      <xs:maxLength value="` + strconv.FormatInt(col.Length, 10) + "\" />\n")
+			bw.WriteString("                    </xs:restriction>\n")
+			bw.WriteString("                  </xs:simpleType>\n")
+			bw.WriteString("                </xs:element>\n")
+			continue
+		}
+		bw.WriteString(`                <xs:element name="` + name + `" type="` + xsType + `"` + occurs + " />\n")
+	}
+
+	bw.WriteString("              </xs:sequence>\n")
+	bw.WriteString("            </xs:complexType>\n")
+	bw.WriteString("          </xs:element>\n")
+	bw.WriteString("        </xs:choice>\n")
+	bw.WriteString("      </xs:complexType>\n")
+	if len(keys) > 0 {
+		bw.WriteString("      <xs:unique name=\"Constraint1\" msdata:PrimaryKey=\"true\">\n")
+		bw.WriteString(`        <xs:selector xpath=".//` + tn + "\" />\n")
+		for _, k := range keys {
+			bw.WriteString(`        <xs:field xpath="` + k + "\" />\n")
+		}
+		bw.WriteString("      </xs:unique>\n")
+	}
+	bw.WriteString("    </xs:element>\n")
+	bw.WriteString("  </xs:schema>\n")
+}
+
+// writeXMLRow - writes a row element with attributes and one child element for each non-null cell
+func (dt *DataTable) writeXMLRow(bw *bufio.Writer, indent, tn, attrs string, row *Row) {
+	bw.WriteString(indent + "<" + tn + attrs + ">\n")
+	for i, col := range dt.Columns {
+		if i >= len(row.Cells) || row.Cells[i].Value == nil {
+			continue
+		}
+		name := xmlEncodeName(col.Name)
+		bw.WriteString(indent + "  <" + name + ">")
+		xml.EscapeText(bw, []byte(formatXMLValue(row.Cells[i].Value, col)))
+		bw.WriteString("</" + name + ">\n")
+	}
+	bw.WriteString(indent + "</" + tn + ">\n")
+}
+
+// writeDiffGram - writes the rows as a DiffGram against the original table
+func (dt *DataTable) writeDiffGram(bw *bufio.Writer, ds, tn string, orig *DataTable) {
+	bw.WriteString(`<diffgr:diffgram xmlns:msdata="` + xmlnsMSData + `" xmlns:diffgr="` + xmlnsDiffGram + "\">\n")
+	bw.WriteString("  <" + ds + ">\n")
+
+	rowAttrs := func(id, order int) string {
+		return fmt.Sprintf(` diffgr:id="%s%d" msdata:rowOrder="%d"`, tn, id, order)
+	}
+
+	// Original rows by primary key, or by position
+	var curKey []int
+	origByKey := make(map[string]int)
+	if orig != nil {
+		var origKey []int
+		for _, k := range dt.PrimaryKey() {
+			curKey = append(curKey, dt.columnIndex(k))
+			origKey = append(origKey, orig.columnIndex(k))
+		}
+		for i := range orig.Rows {
+			k := strconv.Itoa(i)
+			if len(origKey) > 0 {
+				k = rowKeyString(&orig.Rows[i], origKey)
+			}
+			origByKey[k] = i
+		}
+	}
+
+	matched := make(map[int]bool)
+	var before []int // indexes of current rows whose original is written in the before block
+	beforeOrig := make(map[int]int)
+	for i := range dt.Rows {
+		attrs := rowAttrs(i+1, i)
+		j, found := -1, false
+		if orig != nil {
+			k := strconv.Itoa(i)
+			if len(curKey) > 0 {
+				k = rowKeyString(&dt.Rows[i], curKey)
+			}
+			j, found = origByKey[k]
+		}
+
+		switch {
+		case !found:
+			attrs += ` diffgr:hasChanges="inserted"`
+		case !dt.xmlRowEqual(&dt.Rows[i], orig, &orig.Rows[j]):
+			attrs += ` diffgr:hasChanges="modified"`
+			before = append(before, i)
+			beforeOrig[i] = j
+		}
+		if found {
+			matched[j] = true
+		}
+		dt.writeXMLRow(bw, "    ", tn, attrs, &dt.Rows[i])
+	}
+	bw.WriteString("  </" + ds + ">\n")
+
+	var deleted []int
+	if orig != nil {
+		for j := range orig.Rows {
+			if !matched[j] {
+				deleted = append(deleted, j)
+			}
+		}
+	}
+
+	if len(before) > 0 || len(deleted) > 0 {
+		bw.WriteString("  <diffgr:before>\n")
+		for _, i := range before {
+			orig.writeXMLRow(bw, "    ", tn, rowAttrs(i+1, i), &orig.Rows[beforeOrig[i]])
+		}
+		for n, j := range deleted {
+			orig.writeXMLRow(bw, "    ", tn, rowAttrs(len(dt.Rows)+n+1, j), &orig.Rows[j])
+		}
+		bw.WriteString("  </diffgr:before>\n")
+	}
+	bw.WriteString("</diffgr:diffgram>\n")
+}
+
+// xmlRowEqual - returns true if the cells of a row equal the cells of the same columns of an original row
+func (dt *DataTable) xmlRowEqual(row *Row, orig *DataTable, origRow *Row) bool {
+	for i, col := range dt.Columns {
+		j := orig.columnIndex(col.Name)
+		if j == -1 {
+			continue
+		}
+		if !valuesEqual(cellValue(row, i), cellValue(origRow, j)) {
+			return false
+		}
+	}
+	return true
+}
+
+// xmlSchemaType - returns the XSD type of a column
+func xmlSchemaType(col Column) string {
+	switch strings.ToUpper(col.DBType) {
+	case "DECIMAL", "NUMERIC", "MONEY", "SMALLMONEY":
+		return "xs:decimal"
+	}
+	if col.Type == nil {
+		return "xs:string"
+	}
+
+	switch col.Type {
+	case reflect.TypeOf(time.Time{}):
+		return "xs:dateTime"
+	case reflect.TypeOf([]byte{}):
+		return "xs:base64Binary"
+	}
+
+	switch col.Type.Kind() {
+	case reflect.Bool:
+		return "xs:boolean"
+	case reflect.Int8:
+		return "xs:byte"
+	case reflect.Uint8:
+		return "xs:unsignedByte"
+	case reflect.Int16:
+		return "xs:short"
+	case reflect.Uint16:
+		return "xs:unsignedShort"
+	case reflect.Int32:
+		return "xs:int"
+	case reflect.Uint32:
+		return "xs:unsignedInt"
+	case reflect.Int, reflect.Int64:
+		return "xs:long"
+	case reflect.Uint, reflect.Uint64:
+		return "xs:unsignedLong"
+	case reflect.Float32:
+		return "xs:float"
+	case reflect.Float64:
+		return "xs:double"
+	}
+	return "xs:string"
+}
+
+// xmlColumnType - returns the Go type and database type of an XSD type
+func xmlColumnType(xsType string) (reflect.Type, string) {
+	if i := strings.IndexByte(xsType, ':'); i != -1 {
+		xsType = xsType[i+1:]
+	}
+
+	switch xsType {
+	case "boolean":
+		return reflect.TypeOf(false), ""
+	case "byte":
+		return reflect.TypeOf(int8(0)), ""
+	case "unsignedByte":
+		return reflect.TypeOf(uint8(0)), ""
+	case "short":
+		return reflect.TypeOf(int16(0)), ""
+	case "unsignedShort":
+		return reflect.TypeOf(uint16(0)), ""
+	case "int":
+		return reflect.TypeOf(int32(0)), ""
+	case "unsignedInt":
+		return reflect.TypeOf(uint32(0)), ""
+	case "long", "integer":
+		return reflect.TypeOf(int64(0)), ""
+	case "unsignedLong":
+		return reflect.TypeOf(uint64(0)), ""
+	case "float":
+		return reflect.TypeOf(float32(0)), ""
+	case "double":
+		return reflect.TypeOf(0.0), ""
+	case "decimal":
+		return reflect.TypeOf(0.0), "DECIMAL"
+	case "dateTime", "date":
+		return reflect.TypeOf(time.Time{}), ""
+	case "base64Binary":
+		return reflect.TypeOf([]byte{}), ""
+	}
+	return reflect.TypeOf(""), ""
+}
+
+// formatXMLValue - formats a cell value as XSD text
+func formatXMLValue(value interface{}, col Column) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		// Drivers return DECIMAL and text columns as []byte, which are only binary data in base64Binary
+		if xmlSchemaType(col) == "xs:base64Binary" {
+			return base64.StdEncoding.EncodeToString(v)
+		}
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return formatXMLFloat(float64(v), 32)
+	case float64:
+		if xmlSchemaType(col) == "xs:decimal" {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return formatXMLFloat(v, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func formatXMLFloat(f float64, bits int) string {
+	switch {
+	case f != f:
+		return "NaN"
+	case f > 0 && f*0.5 == f:
+		return "INF"
+	case f < 0 && f*0.5 == f:
+		return "-INF"
+	}
+	return strconv.FormatFloat(f, 'G', -1, bits)
+}
+
+// xmlNode - an element of a parsed XML document
+type xmlNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Children []*xmlNode
+	Text     string
+}
+
+func (n *xmlNode) attr(space, local string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == local && (space == "" || a.Name.Space == space) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *xmlNode) child(space, local string) *xmlNode {
+	for _, c := range n.Children {
+		if c.Name.Local == local && c.Name.Space == space {
+			return c
+		}
+	}
+	return nil
+}
+
+// parseXMLTree - reads an XML document into a tree of elements
+func parseXMLTree(r io.Reader) (*xmlNode, error) {
+	dec := xml.NewDecoder(r)
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &xmlNode{Name: t.Name, Attrs: t.Attr}
+			if len(stack) > 0 {
+				p := stack[len(stack)-1]
+				p.Children = append(p.Children, n)
+			} else if root == nil {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("the document has no root element")
+	}
+	return root, nil
+}
+
+// ReadXML - reads a table written by WriteXML or by ADO.NET DataTable.WriteXml. Column types, lengths,
+// nullability and the primary key come from the inline schema. Without a schema, columns are read as
+// strings in the order they first appear. Missing elements are read as NULL. For a DiffGram, the
+// current rows are read and the before block is ignored
+func ReadXML(r io.Reader) (*DataTable, error) {
+	root, err := parseXMLTree(r)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := root
+	if root.Name.Space == xmlnsDiffGram && root.Name.Local == "diffgram" {
+		ds = nil
+		for _, c := range root.Children {
+			if c.Name.Space != xmlnsDiffGram {
+				ds = c
+				break
+			}
+		}
+		if ds == nil {
+			return NewDataTable(""), nil
+		}
+	}
+
+	var dt *DataTable
+	var tn string
+	if schema := ds.child(xmlnsSchema, "schema"); schema != nil {
+		if dt, tn, err = readXMLSchema(schema); err != nil {
+			return nil, err
+		}
+	} else {
+		// Without a schema the first element is a row of the table
+		if len(ds.Children) > 0 {
+			tn = ds.Children[0].Name.Local
+		}
+		dt = NewDataTable(xmlDecodeName(tn))
+		var cols []Column
+		seen := make(map[string]bool)
+		for _, c := range ds.Children {
+			if c.Name.Local != tn {
+				continue
+			}
+			for _, cell := range c.Children {
+				name := xmlDecodeName(cell.Name.Local)
+				if !seen[strings.ToLower(name)] {
+					seen[strings.ToLower(name)] = true
+					cols = append(cols, Column{Name: name, Type: reflect.TypeOf("")})
+				}
+			}
+		}
+		dt.AddColumns(cols)
+	}
+
+	for _, c := range ds.Children {
+		if c.Name.Local != tn || c.Name.Space == xmlnsSchema {
+			continue
+		}
+
+		row := dt.NewRow()
+		for _, cell := range c.Children {
+			idx := dt.columnIndex(xmlDecodeName(cell.Name.Local))
+			if idx == -1 {
+				continue
+			}
+			v, err := parseXMLValue(cell.Text, dt.Columns[idx])
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %s: %w", len(dt.Rows), dt.Columns[idx].Name, err)
+			}
+			row.Cells[idx].Value = v
+		}
+		dt.AddRow(&row)
+	}
+	return dt, nil
+}
+
+// readXMLSchema - returns an empty table and its row element name from an inline XSD schema
+func readXMLSchema(schema *xmlNode) (*DataTable, string, error) {
+	var dsElem *xmlNode
+	for _, c := range schema.Children {
+		if c.Name.Space == xmlnsSchema && c.Name.Local == "element" && strings.EqualFold(c.attr(xmlnsMSData, "IsDataSet"), "true") {
+			dsElem = c
+			break
+		}
+	}
+	if dsElem == nil {
+		return nil, "", errors.New("the schema has no data set element")
+	}
+
+	var tables []*xmlNode
+	if ct := dsElem.child(xmlnsSchema, "complexType"); ct != nil {
+		for _, group := range ct.Children {
+			for _, e := range group.Children {
+				if e.Name.Space == xmlnsSchema && e.Name.Local == "element" {
+					tables = append(tables, e)
+				}
+			}
+		}
+	}
+	if len(tables) == 0 {
+		return nil, "", errors.New("the schema has no table element")
+	}
+
+	table := tables[0]
+	if main := dsElem.attr(xmlnsMSData, "MainDataTable"); main != "" {
+		for _, t := range tables {
+			if t.attr("", "name") == main {
+				table = t
+				break
+			}
+		}
+	}
+	tn := table.attr("", "name")
+
+	var cols []Column
+	if ct := table.child(xmlnsSchema, "complexType"); ct != nil {
+		if seq := ct.child(xmlnsSchema, "sequence"); seq != nil {
+			for _, e := range seq.Children {
+				if e.Name.Space != xmlnsSchema || e.Name.Local != "element" {
+					continue
+				}
+
+				col := Column{Name: xmlDecodeName(e.attr("", "name"))}
+				xsType := e.attr("", "type")
+				if st := e.child(xmlnsSchema, "simpleType"); st != nil {
+					if res := st.child(xmlnsSchema, "restriction"); res != nil {
+						xsType = res.attr("", "base")
+						if ml := res.child(xmlnsSchema, "maxLength"); ml != nil {
+							col.Length, _ = strconv.ParseInt(ml.attr("", "value"), 10, 64)
+						}
+					}
+				}
+				col.Type, col.DBType = xmlColumnType(xsType)
+				col.NotNull = e.attr("", "minOccurs") != "0"
+				cols = append(cols, col)
+			}
+		}
+	}
+
+	dt := NewDataTable(xmlDecodeName(tn))
+	dt.AddColumns(cols)
+
+	for _, u := range dsElem.Children {
+		if u.Name.Space != xmlnsSchema || u.Name.Local != "unique" || !strings.EqualFold(u.attr(xmlnsMSData, "PrimaryKey"), "true") {
+			continue
+		}
+		var keys []string
+		for _, f := range u.Children {
+			if f.Name.Local == "field" {
+				keys = append(keys, xmlDecodeName(strings.TrimPrefix(f.attr("", "xpath"), "mstns:")))
+			}
+		}
+		if err := dt.SetPrimaryKey(keys...); err != nil {
+			return nil, "", err
+		}
+	}
+	return dt, tn, nil
+}
+
+// parseXMLValue - parses XSD text as a value of the column type
+func parseXMLValue(s string, col Column) (interface{}, error) {
+	if col.Type == nil {
+		return s, nil
+	}
+
+	switch col.Type {
+	case reflect.TypeOf(time.Time{}):
+		s = strings.TrimSpace(s)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02Z07:00", "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date and time %q", s)
+	case reflect.TypeOf([]byte{}):
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	}
+
+	switch col.Type.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Bool:
+		switch strings.TrimSpace(s) {
+		case "true", "1":
+			return true, nil
+		case "false", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, col.Type.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(i).Convert(col.Type).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.TrimSpace(s), 10, col.Type.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(u).Convert(col.Type).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		s = strings.TrimSpace(s)
+		switch s {
+		case "INF":
+			s = "+Inf"
+		case "-INF":
+			s = "-Inf"
+		}
+		f, err := strconv.ParseFloat(s, col.Type.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(f).Convert(col.Type).Interface(), nil
+	}
+	return s, nil
+}
+
+// xmlEncodeName - encodes a name as an XML element name the way .NET XmlConvert.EncodeLocalName does,
+// writing characters that are not allowed as _xHHHH_
+func xmlEncodeName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r > 0x7f
+		if i > 0 {
+			valid = valid || (r >= '0' && r <= '9') || r == '-' || r == '.'
+		}
+		if r == '_' && isXMLEscape(name[i:]) {
+			valid = false
+		}
+		if valid {
+			sb.WriteRune(r)
+			continue
+		}
+		fmt.Fprintf(&sb, "_x%04X_", r)
+	}
+	return sb.String()
+}
+
+// xmlDecodeName - decodes _xHHHH_ sequences of an encoded XML name
+func xmlDecodeName(name string) string {
+	if !strings.Contains(name, "_x") {
+		return name
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if isXMLEscape(name[i:]) {
+			r, _ := strconv.ParseUint(name[i+2:i+6], 16, 32)
+			sb.WriteRune(rune(r))
+			i += 6
+			continue
+		}
+		sb.WriteByte(name[i])
+	}
+	return sb.String()
+}
+
+// isXMLEscape - returns true if s starts with an _xHHHH_ sequence
+func isXMLEscape(s string) bool {
+	if len(s) < 7 || s[0] != '_' || s[1] != 'x' || s[6] != '_' {
+		return false
+	}
+	_, err := strconv.ParseUint(s[2:6], 16, 32)
+	return err == nil
+}
+
+func xmlAttr(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,264 @@
+package datatable
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteXML(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int32(0)), PrimaryKey: true, NotNull: true},
+		{Name: "Customer Name", Type: reflect.TypeOf(""), Length: 40},
+		{Name: "Amount", Type: reflect.TypeOf(0.0), DBType: "DECIMAL"},
+		{Name: "Created", Type: reflect.TypeOf(time.Time{})},
+		{Name: "Paid", Type: reflect.TypeOf(false)},
+		{Name: "Data", Type: reflect.TypeOf([]byte{})},
+	})
+	for _, vals := range [][]interface{}{
+		{int32(1), "Acme & Sons", 12.5, time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC), true, []byte{1, 2, 3}},
+		{int32(2), nil, nil, nil, false, nil},
+	} {
+		r := dt.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		dt.AddRow(&r)
+	}
+
+	var buf bytes.Buffer
+	if err := dt.WriteXML(&buf, XMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<NewDataSet>`,
+		`<xs:element name="NewDataSet" msdata:IsDataSet="true" msdata:MainDataTable="Orders" msdata:UseCurrentLocale="true">`,
+		`<xs:element name="ID" type="xs:int" />`,
+		`<xs:element name="Customer_x0020_Name" minOccurs="0">`,
+		`<xs:maxLength value="40" />`,
+		`<xs:element name="Amount" type="xs:decimal" minOccurs="0" />`,
+		`<xs:element name="Created" type="xs:dateTime" minOccurs="0" />`,
+		`<xs:element name="Data" type="xs:base64Binary" minOccurs="0" />`,
+		`<xs:field xpath="ID" />`,
+		`<Customer_x0020_Name>Acme &amp; Sons</Customer_x0020_Name>`,
+		`<Created>2024-03-01T08:30:00Z</Created>`,
+		`<Data>AQID</Data>`,
+		"  <Orders>\n    <ID>2</ID>\n    <Paid>false</Paid>\n  </Orders>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := dt.WriteXML(&buf, XMLOptions{Mode: XMLIgnoreSchema, DataSetName: "Export"}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "xs:schema") || !strings.Contains(out, "<Export>") {
+		t.Fatalf("unexpected output\n%s", out)
+	}
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	dt := NewDataTable("Orders")
+	dt.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int32(0)), PrimaryKey: true, NotNull: true},
+		{Name: "Customer Name", Type: reflect.TypeOf(""), Length: 40},
+		{Name: "Amount", Type: reflect.TypeOf(0.0), DBType: "DECIMAL"},
+		{Name: "Created", Type: reflect.TypeOf(time.Time{})},
+		{Name: "Paid", Type: reflect.TypeOf(false)},
+		{Name: "Data", Type: reflect.TypeOf([]byte{})},
+	})
+	for _, vals := range [][]interface{}{
+		{int32(1), "Acme & Sons", 12.5, time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC), true, []byte{1, 2, 3}},
+		{int32(2), nil, nil, nil, false, nil},
+	} {
+		r := dt.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		dt.AddRow(&r)
+	}
+
+	var buf bytes.Buffer
+	if err := dt.WriteXML(&buf, XMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadXML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "Orders" || !reflect.DeepEqual(got.Columns, dt.Columns) {
+		t.Fatalf("unexpected schema %s %+v", got.Name, got.Columns)
+	}
+	if len(got.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got.Rows))
+	}
+	for i := range dt.Rows {
+		for j := range dt.Columns {
+			a, b := dt.Rows[i].Cells[j].Value, got.Rows[i].Cells[j].Value
+			if ta, ok := a.(time.Time); ok {
+				if !ta.Equal(b.(time.Time)) {
+					t.Errorf("row %d, column %d: got %v, expected %v", i, j, b, a)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(a, b) {
+				t.Errorf("row %d, column %d: got %#v, expected %#v", i, j, b, a)
+			}
+		}
+	}
+}
+
+func TestXMLRoundTripDecimalBytes(t *testing.T) {
+	dt := NewDataTable("Prices")
+	dt.AddColumns([]Column{{Name: "Price", Type: reflect.TypeOf([]byte{}), DBType: "DECIMAL"}})
+	r := dt.NewRow()
+	r.Cells[0].Value = []byte("12.50")
+	dt.AddRow(&r)
+
+	var buf bytes.Buffer
+	if err := dt.WriteXML(&buf, XMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `type="xs:decimal"`) || !strings.Contains(out, "<Price>12.50</Price>") {
+		t.Fatalf("unexpected output\n%s", out)
+	}
+
+	got, err := ReadXML(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Rows) != 1 || got.Rows[0].Cells[0].Value != 12.5 {
+		t.Fatalf("unexpected rows %+v", got.Rows)
+	}
+}
+
+func TestWriteXMLDiffGram(t *testing.T) {
+	orig := NewDataTable("Orders")
+	orig.AddColumns([]Column{
+		{Name: "ID", Type: reflect.TypeOf(int32(0)), PrimaryKey: true, NotNull: true},
+		{Name: "Customer Name", Type: reflect.TypeOf("")},
+		{Name: "Paid", Type: reflect.TypeOf(false)},
+	})
+	for _, vals := range [][]interface{}{{int32(1), "Acme", true}, {int32(2), nil, false}, {int32(3), nil, true}} {
+		r := orig.NewRow()
+		for i, v := range vals {
+			r.Cells[i].Value = v
+		}
+		orig.AddRow(&r)
+	}
+
+	dt := orig.Copy()
+	dt.Rows[1].Cells[1].Value = "Changed"
+	dt.RemoveAt(2)
+	r := dt.NewRow()
+	r.Cells[0].Value = int32(4)
+	r.Cells[2].Value = false
+	dt.AddRow(&r)
+
+	var buf bytes.Buffer
+	if err := dt.WriteXML(&buf, XMLOptions{Mode: XMLDiffGram, Original: orig}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<diffgr:diffgram xmlns:msdata="urn:schemas-microsoft-com:xml-msdata" xmlns:diffgr="urn:schemas-microsoft-com:xml-diffgram-v1">`,
+		`<Orders diffgr:id="Orders1" msdata:rowOrder="0">`,
+		`<Orders diffgr:id="Orders2" msdata:rowOrder="1" diffgr:hasChanges="modified">`,
+		`<Orders diffgr:id="Orders3" msdata:rowOrder="2" diffgr:hasChanges="inserted">`,
+		"<diffgr:before>\n    <Orders diffgr:id=\"Orders2\" msdata:rowOrder=\"1\">\n      <ID>2</ID>\n      <Paid>false</Paid>",
+		`<Orders diffgr:id="Orders4" msdata:rowOrder="2">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in\n%s", want, out)
+		}
+	}
+
+	got, err := ReadXML(strings.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Rows) != 3 || got.Rows[1].Value("Customer Name") != "Changed" || got.Rows[2].Value("ID") != "4" {
+		t.Fatalf("unexpected rows read from the diffgram %+v", got.Rows)
+	}
+}
+
+func TestReadXMLDotNet(t *testing.T) {
+	// As written by DataTable.WriteXml(writer, XmlWriteMode.WriteSchema) in .NET
+	doc := `<?xml version="1.0" standalone="yes"?>
+<NewDataSet>
+  <xs:schema id="NewDataSet" xmlns="" xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:msdata="urn:schemas-microsoft-com:xml-msdata">
+    <xs:element name="NewDataSet" msdata:IsDataSet="true" msdata:MainDataTable="Items" msdata:UseCurrentLocale="true">
+      <xs:complexType>
+        <xs:choice minOccurs="0" maxOccurs="unbounded">
+          <xs:element name="Items">
+            <xs:complexType>
+              <xs:sequence>
+                <xs:element name="Code" type="xs:string" />
+                <xs:element name="Qty" type="xs:long" minOccurs="0" />
+                <xs:element name="Added" type="xs:dateTime" minOccurs="0" />
+              </xs:sequence>
+            </xs:complexType>
+          </xs:element>
+        </xs:choice>
+      </xs:complexType>
+      <xs:unique name="Constraint1" msdata:PrimaryKey="true">
+        <xs:selector xpath=".//Items" />
+        <xs:field xpath="Code" />
+      </xs:unique>
+    </xs:element>
+  </xs:schema>
+  <Items>
+    <Code>A1</Code>
+    <Qty>5</Qty>
+    <Added>2024-05-06T07:08:09.1234567+08:00</Added>
+  </Items>
+  <Items>
+    <Code>B2</Code>
+  </Items>
+</NewDataSet>`
+
+	dt, err := ReadXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dt.Name != "Items" || len(dt.Rows) != 2 {
+		t.Fatalf("unexpected table %s with %d rows", dt.Name, len(dt.Rows))
+	}
+	if pk := dt.PrimaryKey(); len(pk) != 1 || pk[0] != "Code" || !dt.Columns[0].NotNull || dt.Columns[1].NotNull {
+		t.Fatalf("unexpected columns %+v", dt.Columns)
+	}
+	if v := dt.Rows[0].Value("Qty"); v != int64(5) {
+		t.Fatalf("expected 5, got %#v", v)
+	}
+	if v := dt.Rows[0].Value("Added").(time.Time); v.UTC() != time.Date(2024, 5, 5, 23, 8, 9, 123456700, time.UTC) {
+		t.Fatalf("unexpected time %v", v)
+	}
+	if v := dt.Rows[1].Value("Qty"); v != nil {
+		t.Fatalf("expected NULL, got %#v", v)
+	}
+
+	if _, err := ReadXML(strings.NewReader(strings.Replace(doc, "<Qty>5</Qty>", "<Qty>five</Qty>", 1))); err == nil {
+		t.Fatal("expected an error for an invalid number")
+	}
+}
+
+func TestXMLNameEncoding(t *testing.T) {
+	for _, name := range []string{"Order ID", "1st", "a_x0041_b", "Total:Net"} {
+		enc := xmlEncodeName(name)
+		if dec := xmlDecodeName(enc); dec != name {
+			t.Errorf("%s encoded as %s decoded as %s", name, enc, dec)
+		}
+	}
+	if enc := xmlEncodeName("Order ID"); enc != "Order_x0020_ID" {
+		t.Fatalf("unexpected encoding %s", enc)
+	}
+}